@@ -0,0 +1,199 @@
+package apidoc
+
+import (
+	"gacha/models"
+)
+
+// Message documents one WebSocket message type. Every message is an envelope
+// {type, seq, data, error}; Messages must list every type the WebSocket
+// handler accepts or sends, which a test checks against live traffic.
+type Message struct {
+	Type            string
+	Inbound         bool // Sent by the client rather than the server
+	Summary         string
+	Payload         interface{} // Zero value of the data payload, nil for none
+	OptionalPayload bool        // Data may be omitted even though Payload is set
+	Sequenced       bool        // Carries a session sequence number and is replayed on resume
+	Error           bool        // Carries an error description
+}
+
+// Messages documents every WebSocket message type
+var Messages = []Message{
+	// Inbound
+	{Type: "single_pull", Inbound: true, Summary: "Pull once, answered by gacha_result"},
+	{Type: "ten_pull", Inbound: true, Summary: "Pull ten times, answered by gacha_result"},
+	{Type: "pull", Inbound: true, Summary: "Pull count times, stopping early on a condition, answered by gacha_result", Payload: models.PullRequest{}},
+	{Type: "get_user_info", Inbound: true, Summary: "Request user_info"},
+	{Type: "get_inventory", Inbound: true, Summary: "Request inventory"},
+	{Type: "get_pool", Inbound: true, Summary: "Request pool_info"},
+	{Type: "add_currency", Inbound: true, Summary: "Purchase currency, answered by currency_update", Payload: models.AddCurrencyRequest{}},
+	{Type: "get_missions", Inbound: true, Summary: "Request missions"},
+	{Type: "claim_mission", Inbound: true, Summary: "Claim a completed mission's reward, answered by mission_claimed", Payload: models.MissionClaimRequest{}},
+	{Type: "get_season", Inbound: true, Summary: "Request season"},
+	{Type: "claim_season_tier", Inbound: true, Summary: "Claim a reached season tier's reward, answered by season_tier_claimed", Payload: models.SeasonClaimRequest{}},
+	{Type: "resume", Inbound: true, Summary: "Resume a previous session, replaying the events after lastSeq", Payload: models.ResumeRequest{}},
+	{Type: "ping", Inbound: true, Summary: "Application-level keepalive, answered by pong"},
+
+	// Outbound
+	{Type: "gacha_result", Summary: "Characters obtained by a pull", Payload: models.GachaResult{}, Sequenced: true},
+	{Type: "user_info", Summary: "Balance, pity and tickets, sent on connect and after every change", Payload: models.UserInfoResponse{}, Sequenced: true},
+	{Type: "inventory", Summary: "Full inventory", Payload: models.InventoryResponse{}, Sequenced: true},
+	{Type: "inventory_update", Summary: "Characters newly added to the inventory", Payload: models.InventoryUpdateResponse{}, Sequenced: true},
+	{Type: "pool_info", Summary: "Character pool and rates", Payload: models.PoolInfo{}, Sequenced: true},
+	{Type: "currency_update", Summary: "Balance after a purchase", Payload: models.CurrencyResponse{}, Sequenced: true},
+	{Type: "missions", Summary: "Progress on every mission and achievement", Payload: models.MissionsResponse{}, Sequenced: true},
+	{Type: "mission_claimed", Summary: "A mission whose reward was just granted", Payload: models.MissionStatus{}, Sequenced: true},
+	{Type: "season", Summary: "Progress on the active season pass and the next season", Payload: models.SeasonStatusResponse{}, Sequenced: true},
+	{Type: "season_tier_claimed", Summary: "A season tier whose reward was just granted", Payload: models.SeasonTierStatus{}, Sequenced: true},
+	{Type: "daily_reward", Summary: "Daily login reward granted, sent on the first connection or activity of the day", Payload: models.DailyReward{}, Sequenced: true},
+	{Type: "session", Summary: "Session the connection is attached to, sent on connect and resume", Payload: models.SessionResponse{}},
+	{Type: "error", Summary: "A message failed; spending limit rejections carry their details", Payload: models.LimitErrorResponse{}, OptionalPayload: true, Error: true},
+	{Type: "rate_limited", Summary: "A message was dropped by a rate limit", Payload: models.RateLimitResponse{}, Error: true},
+	{Type: "pong", Summary: "Reply to ping"},
+}
+
+// AsyncAPIDocument is an AsyncAPI 3.0 document
+type AsyncAPIDocument struct {
+	AsyncAPI           string                       `json:"asyncapi"`
+	Info               Info                         `json:"info"`
+	DefaultContentType string                       `json:"defaultContentType"`
+	Channels           map[string]Channel           `json:"channels"`
+	Operations         map[string]AsyncAPIOperation `json:"operations"`
+	Components         AsyncAPIComponents           `json:"components"`
+}
+
+// Channel is a WebSocket endpoint and the messages exchanged over it
+type Channel struct {
+	Address  string               `json:"address"`
+	Messages map[string]Reference `json:"messages"`
+}
+
+// AsyncAPIOperation is a message the server sends or receives
+type AsyncAPIOperation struct {
+	Action   string      `json:"action"` // "send" or "receive", from the server's point of view
+	Channel  Reference   `json:"channel"`
+	Summary  string      `json:"summary"`
+	Messages []Reference `json:"messages"`
+}
+
+// Reference points to another part of the document
+type Reference struct {
+	Ref string `json:"$ref"`
+}
+
+// AsyncAPIMessage describes one message type
+type AsyncAPIMessage struct {
+	Name    string  `json:"name"`
+	Title   string  `json:"title"`
+	Summary string  `json:"summary"`
+	Payload *Schema `json:"payload"`
+}
+
+// AsyncAPIComponents holds the messages and schemas operations refer to
+type AsyncAPIComponents struct {
+	Messages map[string]AsyncAPIMessage `json:"messages"`
+	Schemas  map[string]*Schema         `json:"schemas"`
+}
+
+// wsChannel names the WebSocket channel
+const wsChannel = "ws"
+
+// codecDescription explains how the envelope is framed by each codec
+const codecDescription = "WebSocket API of the gacha server at /ws. Every message is an envelope " +
+	"{type, seq, data, error}. The codec is negotiated with Sec-WebSocket-Protocol: with gacha.json " +
+	"(the default) frames are text and data is a string holding the JSON encoding of the payload; " +
+	"with gacha.msgpack frames are binary MessagePack and data is the payload itself. The schemas " +
+	"describe the decoded envelope, with data as the payload value."
+
+// AsyncAPI builds the AsyncAPI document of Messages
+func AsyncAPI() AsyncAPIDocument {
+	registry := NewRegistry("#/components/schemas/")
+	doc := AsyncAPIDocument{
+		AsyncAPI: "3.0.0",
+		Info: Info{
+			Title:       "Gacha WebSocket API",
+			Version:     Version,
+			Description: codecDescription,
+		},
+		DefaultContentType: "application/json",
+		Channels: map[string]Channel{
+			wsChannel: {Address: "/ws", Messages: make(map[string]Reference)},
+		},
+		Operations: make(map[string]AsyncAPIOperation),
+		Components: AsyncAPIComponents{Messages: make(map[string]AsyncAPIMessage)},
+	}
+
+	for _, msg := range Messages {
+		doc.Components.Messages[msg.Type] = AsyncAPIMessage{
+			Name:    msg.Type,
+			Title:   msg.Type,
+			Summary: msg.Summary,
+			Payload: msg.envelope(registry),
+		}
+		doc.Channels[wsChannel].Messages[msg.Type] = Reference{Ref: "#/components/messages/" + msg.Type}
+
+		action := "send"
+		if msg.Inbound {
+			action = "receive"
+		}
+		doc.Operations[action+"_"+msg.Type] = AsyncAPIOperation{
+			Action:   action,
+			Channel:  Reference{Ref: "#/channels/" + wsChannel},
+			Summary:  msg.Summary,
+			Messages: []Reference{{Ref: "#/channels/" + wsChannel + "/messages/" + msg.Type}},
+		}
+	}
+
+	doc.Components.Schemas = registry.Definitions()
+	return doc
+}
+
+// FindMessage returns the documentation of a message type
+func FindMessage(msgType string) (Message, bool) {
+	for _, msg := range Messages {
+		if msg.Type == msgType {
+			return msg, true
+		}
+	}
+	return Message{}, false
+}
+
+// JSONSchema returns a self-contained JSON Schema of the message's decoded envelope
+func (msg Message) JSONSchema() *Schema {
+	registry := NewRegistry("#/$defs/")
+	schema := msg.envelope(registry)
+	schema.Dialect = Dialect2020
+	schema.Title = msg.Type
+	schema.Description = msg.Summary
+	if defs := registry.Definitions(); len(defs) > 0 {
+		schema.Defs = defs
+	}
+	return schema
+}
+
+// envelope describes the message's decoded envelope, looking up its payload schema in registry
+func (msg Message) envelope(registry *Registry) *Schema {
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"type": {Const: msg.Type},
+		},
+		Required: []string{"type"},
+	}
+
+	if msg.Sequenced {
+		schema.Properties["seq"] = &Schema{Type: "integer", Minimum: float(1), Description: "Session sequence number"}
+		schema.Required = append(schema.Required, "seq")
+	}
+	if msg.Payload != nil {
+		schema.Properties["data"] = registry.Schema(msg.Payload)
+		if !msg.OptionalPayload {
+			schema.Required = append(schema.Required, "data")
+		}
+	}
+	if msg.Error {
+		schema.Properties["error"] = &Schema{Type: "string"}
+		schema.Required = append(schema.Required, "error")
+	}
+	return schema
+}
@@ -0,0 +1,221 @@
+package apidoc
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gacha/models"
+)
+
+// Document is an OpenAPI 3.1 document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of one path, keyed by lower-case method
+type PathItem map[string]*Operation
+
+// Operation describes one route
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Tags        []string              `json:"tags"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter describes a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes a request body
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response to an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType describes a body in one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas and security schemes operations refer to
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes how a request authenticates
+type SecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
+
+// adminSecurity names the security scheme of admin routes
+const adminSecurity = "adminToken"
+
+// LegacyError is the body of /api v1 error responses. Spending limit
+// rejections carry the fields of models.LimitErrorResponse as well.
+type LegacyError struct {
+	Error string `json:"error"`
+}
+
+// Version is the version of the documented API
+const Version = "2.0.0"
+
+// OpenAPI builds the OpenAPI document of Routes
+func OpenAPI() Document {
+	registry := NewRegistry("#/components/schemas/")
+	doc := Document{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:   "Gacha API",
+			Version: Version,
+			Description: "HTTP API of the gacha server. Routes under /api/v2 validate their " +
+				"request bodies and report errors in the ErrorResponse envelope; the /api " +
+				"routes are kept for backward compatibility. Real-time play uses the " +
+				"WebSocket at /ws, described by the AsyncAPI document.",
+		},
+		Paths: make(map[string]PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				adminSecurity: {Type: "apiKey", In: "header", Name: "X-Admin-Token"},
+			},
+		},
+	}
+
+	for _, route := range Routes {
+		path := OpenAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(PathItem)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = route.operation(registry)
+	}
+
+	doc.Components.Schemas = registry.Definitions()
+	return doc
+}
+
+// OpenAPIPath converts a Gin path, e.g. /users/:username, to an OpenAPI path
+func OpenAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operation describes a route, looking up its schemas in registry
+func (route Route) operation(registry *Registry) *Operation {
+	op := &Operation{
+		OperationID: route.operationID(),
+		Summary:     route.Summary,
+		Tags:        []string{route.Tag},
+		Responses:   make(map[string]Response),
+	}
+
+	for _, param := range route.Params {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        param.Name,
+			In:          param.In,
+			Description: param.Description,
+			Required:    param.In == "path",
+			Schema:      registry.Schema(param.Type),
+		})
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: !route.OptionalBody,
+			Content:  jsonContent(registry.Schema(route.Request)),
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	switch {
+	case route.Produces != "":
+		success.Content = map[string]MediaType{route.Produces: {Schema: &Schema{Type: "string"}}}
+	case route.Response != nil:
+		success.Content = jsonContent(registry.Schema(route.Response))
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	var errorBody interface{} = LegacyError{}
+	if strings.HasPrefix(route.Path, "/api/v2/") {
+		errorBody = models.ErrorResponse{}
+	}
+	for _, code := range route.Errors {
+		body := errorBody
+		if override, ok := route.ErrorBodies[code]; ok {
+			body = override
+		}
+		op.Responses[strconv.Itoa(code)] = Response{
+			Description: http.StatusText(code),
+			Content:     jsonContent(registry.Schema(body)),
+		}
+	}
+
+	if route.Admin {
+		op.Security = []map[string][]string{{adminSecurity: {}}}
+	}
+	return op
+}
+
+// operationID derives a unique camel case ID from the method and path,
+// e.g. postApiV2GachaPull
+func (route Route) operationID() string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(route.Method))
+	for _, word := range strings.FieldsFunc(route.Path, func(r rune) bool {
+		return r == '/' || r == '-' || r == ':' || r == '.'
+	}) {
+		id.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return id.String()
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+// Operations lists the documented routes as "METHOD path", sorted, in Gin syntax
+func Operations() []string {
+	ops := make([]string, len(Routes))
+	for i, route := range Routes {
+		ops[i] = route.Method + " " + route.Path
+	}
+	sort.Strings(ops)
+	return ops
+}
@@ -0,0 +1,200 @@
+package apidoc
+
+import (
+	"net/http"
+
+	"gacha/models"
+)
+
+// Route documents one HTTP route. Routes must list every route registered by
+// routes.SetupRoutes; a test fails when the two drift apart.
+type Route struct {
+	Method       string
+	Path         string // In Gin syntax, e.g. /api/admin/users/:username
+	Tag          string
+	Summary      string
+	Params       []Param
+	Request      interface{}         // Zero value of the JSON request body, nil for none
+	OptionalBody bool                // The request body may be omitted
+	Response     interface{}         // Zero value of the JSON success body, nil for none
+	Produces     string              // Content type of a success body that is not JSON
+	Status       int                 // Success status, 200 when zero
+	Errors       []int               // Error statuses, with the body of the route's API version
+	ErrorBodies  map[int]interface{} // Error bodies that differ from the API version's
+	Admin        bool                // Requires the admin token
+}
+
+// Param documents a path or query parameter
+type Param struct {
+	Name        string
+	In          string // "path" or "query"
+	Type        interface{}
+	Description string
+}
+
+// bannerParam selects the banner of the calculator and disclosure
+var bannerParam = Param{Name: "banner", In: "query", Type: "", Description: "Banner ID, the default banner when omitted"}
+
+// spendingLimitBody is the v1 body of spending limit rejections
+var spendingLimitBody = map[int]interface{}{http.StatusForbidden: models.LimitErrorResponse{}}
+
+// Routes documents every HTTP route
+var Routes = []Route{
+	// Infrastructure
+	{Method: http.MethodGet, Path: "/ws", Tag: "realtime", Summary: "Upgrade to the WebSocket API described by /asyncapi.json",
+		Status: http.StatusSwitchingProtocols, Errors: []int{http.StatusBadRequest}},
+	{Method: http.MethodGet, Path: "/metrics", Tag: "operations", Summary: "Prometheus metrics", Produces: "text/plain"},
+	{Method: http.MethodGet, Path: "/healthz", Tag: "operations", Summary: "Liveness", Response: models.HealthResponse{}},
+	{Method: http.MethodGet, Path: "/readyz", Tag: "operations", Summary: "Readiness, failing while draining or when a dependency check fails",
+		Response: models.ReadinessResponse{}, Errors: []int{http.StatusServiceUnavailable},
+		ErrorBodies: map[int]interface{}{http.StatusServiceUnavailable: models.ReadinessResponse{}}},
+	{Method: http.MethodGet, Path: "/version", Tag: "operations", Summary: "Build and configuration version", Response: models.VersionResponse{}},
+	{Method: http.MethodGet, Path: "/openapi.json", Tag: "docs", Summary: "This OpenAPI document", Produces: "application/json"},
+	{Method: http.MethodGet, Path: "/docs", Tag: "docs", Summary: "Swagger UI for this document", Produces: "text/html"},
+	{Method: http.MethodGet, Path: "/asyncapi.json", Tag: "docs", Summary: "AsyncAPI document of the WebSocket API", Produces: "application/json"},
+	{Method: http.MethodGet, Path: "/schemas/ws/:type", Tag: "docs", Summary: "JSON Schema of a WebSocket message type",
+		Params:   []Param{{Name: "type", In: "path", Type: "", Description: "Message type, e.g. gacha_result"}},
+		Produces: "application/schema+json", Errors: []int{http.StatusNotFound}},
+
+	// v1 gacha
+	{Method: http.MethodPost, Path: "/api/gacha/pull", Tag: "gacha", Summary: "Pull once, or count times with an optional stop condition, for the default user",
+		Request: models.PullRequest{}, OptionalBody: true, Response: models.GachaResult{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden}, ErrorBodies: spendingLimitBody},
+	{Method: http.MethodPost, Path: "/api/gacha/pull-ten", Tag: "gacha", Summary: "Ten pull for the default user",
+		Response: models.GachaResult{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden}, ErrorBodies: spendingLimitBody},
+	{Method: http.MethodGet, Path: "/api/gacha/pool", Tag: "gacha", Summary: "Character pool and rates", Response: models.PoolInfo{}},
+	{Method: http.MethodGet, Path: "/api/gacha/calculator", Tag: "gacha", Summary: "Odds and expected cost of obtaining a character",
+		Params: calculatorParams, Response: models.CalculatorResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: http.MethodGet, Path: "/api/gacha/disclosure", Tag: "gacha", Summary: "Per-character probability disclosure, as JSON or HTML",
+		Params: disclosureParams, Response: models.Disclosure{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+
+	// v1 user
+	{Method: http.MethodGet, Path: "/api/user/info", Tag: "user", Summary: "Default user's balance, pity and tickets", Response: models.UserInfoResponse{}},
+	{Method: http.MethodGet, Path: "/api/user/inventory", Tag: "user", Summary: "Default user's characters", Response: models.InventoryResponse{}},
+	{Method: http.MethodPost, Path: "/api/user/add-currency", Tag: "user", Summary: "Purchase currency",
+		Request: models.AddCurrencyRequest{}, Response: models.CurrencyResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusForbidden}, ErrorBodies: spendingLimitBody},
+	{Method: http.MethodPost, Path: "/api/user/register", Tag: "user", Summary: "Register a user in an age bracket",
+		Request: models.RegisterRequest{}, Response: models.UserInfoResponse{}, Errors: []int{http.StatusBadRequest}},
+	{Method: http.MethodGet, Path: "/api/user/limits", Tag: "user", Summary: "Spending limits and usage this month", Response: models.LimitsResponse{}},
+	{Method: http.MethodPut, Path: "/api/user/limits", Tag: "user", Summary: "Set a self-imposed monthly limit; raising or removing it waits for the next reset",
+		Request: models.SetLimitRequest{}, Response: models.LimitsResponse{}, Errors: []int{http.StatusBadRequest}},
+	{Method: http.MethodGet, Path: "/api/user/daily", Tag: "user", Summary: "Login calendar, streak and next daily reward", Response: models.DailyStatusResponse{}},
+
+	// v1 shop
+	{Method: http.MethodGet, Path: "/api/shop", Tag: "shop", Summary: "Shop offers and the items they sell", Response: models.ShopResponse{}},
+	{Method: http.MethodPost, Path: "/api/shop/buy", Tag: "shop", Summary: "Buy a shop offer with currency",
+		Request: models.ShopPurchaseRequest{}, Response: models.ShopPurchaseResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}, ErrorBodies: spendingLimitBody},
+
+	// v1 missions
+	{Method: http.MethodGet, Path: "/api/missions", Tag: "missions", Summary: "Progress on every mission and achievement", Response: models.MissionsResponse{}},
+	{Method: http.MethodPost, Path: "/api/missions/:id/claim", Tag: "missions", Summary: "Claim a completed mission's reward",
+		Params: []Param{{Name: "id", In: "path", Type: ""}}, Response: models.MissionStatus{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+
+	// v1 season pass
+	{Method: http.MethodGet, Path: "/api/season", Tag: "season", Summary: "Progress on the active season pass and the next season", Response: models.SeasonStatusResponse{}},
+	{Method: http.MethodPost, Path: "/api/season/claim", Tag: "season", Summary: "Claim a reached tier's reward on the free or premium track",
+		Request: models.SeasonClaimRequest{}, Response: models.SeasonTierStatus{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: http.MethodPost, Path: "/api/season/premium", Tag: "season", Summary: "Unlock the active season's premium track with currency",
+		Response: models.SeasonStatusResponse{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden}, ErrorBodies: spendingLimitBody},
+
+	// v1 fairness
+	{Method: http.MethodGet, Path: "/api/fairness", Tag: "fairness", Summary: "Active server seed commitment",
+		Response: models.FairnessCommitment{}, Errors: []int{http.StatusBadRequest}},
+	{Method: http.MethodPost, Path: "/api/fairness/client-seed", Tag: "fairness", Summary: "Change the client seed",
+		Request: models.ClientSeedRequest{}, Response: models.FairnessCommitment{}, Errors: []int{http.StatusBadRequest}},
+	{Method: http.MethodPost, Path: "/api/fairness/rotate", Tag: "fairness", Summary: "Reveal the server seed and commit to a new one",
+		Response: models.RevealedSeed{}, Errors: []int{http.StatusBadRequest}},
+	{Method: http.MethodPost, Path: "/api/fairness/verify", Tag: "fairness", Summary: "Recompute a pull from a revealed server seed",
+		Request: models.VerifyRequest{}, Response: models.VerifyResponse{}, Errors: []int{http.StatusBadRequest}},
+
+	// v1 admin
+	{Method: http.MethodPost, Path: "/api/admin/grant", Tag: "admin", Summary: "Grant currency to a user", Admin: true,
+		Request: models.AdminCurrencyRequest{}, Response: models.CurrencyResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodPost, Path: "/api/admin/refund", Tag: "admin", Summary: "Refund up to this month's pull spending to a user", Admin: true,
+		Request: models.AdminCurrencyRequest{}, Response: models.CurrencyResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodPost, Path: "/api/admin/items/grant", Tag: "admin", Summary: "Grant items such as pull tickets to a user", Admin: true,
+		Request: models.AdminItemRequest{}, Response: models.ItemsResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodPut, Path: "/api/admin/users/:username", Tag: "admin", Summary: "Change a user's age bracket", Admin: true,
+		Params:  []Param{{Name: "username", In: "path", Type: ""}},
+		Request: models.AdminUserUpdateRequest{}, Response: models.LimitsResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodGet, Path: "/api/admin/audit", Tag: "admin", Summary: "Query the audit log", Admin: true,
+		Params: []Param{
+			{Name: "user", In: "query", Type: "", Description: "Username"},
+			{Name: "actor", In: "query", Type: "", Description: "e.g. user:alice or admin:bob"},
+			{Name: "action", In: "query", Type: ""},
+			{Name: "from", In: "query", Type: "", Description: "RFC 3339 time, inclusive"},
+			{Name: "to", In: "query", Type: "", Description: "RFC 3339 time, exclusive"},
+			{Name: "limit", In: "query", Type: 0, Description: "Most recent entries to return"},
+		},
+		Response: models.AuditQueryResponse{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden}},
+
+	// v2 gacha
+	{Method: http.MethodPost, Path: "/api/v2/gacha/pull", Tag: "gacha", Summary: "Pull once, or count times with an optional stop condition, for the default user",
+		Request: models.PullRequest{}, OptionalBody: true, Response: models.GachaResult{},
+		Errors: []int{http.StatusBadRequest, http.StatusPaymentRequired, http.StatusForbidden, http.StatusTooManyRequests}},
+	{Method: http.MethodPost, Path: "/api/v2/gacha/pull-ten", Tag: "gacha", Summary: "Ten pull for the default user",
+		Response: models.GachaResult{}, Errors: []int{http.StatusPaymentRequired, http.StatusForbidden, http.StatusTooManyRequests}},
+	{Method: http.MethodGet, Path: "/api/v2/gacha/pool", Tag: "gacha", Summary: "Character pool and rates", Response: models.PoolInfo{}},
+	{Method: http.MethodGet, Path: "/api/v2/gacha/calculator", Tag: "gacha", Summary: "Odds and expected cost of obtaining a character",
+		Params: calculatorParams, Response: models.CalculatorResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: http.MethodGet, Path: "/api/v2/gacha/disclosure", Tag: "gacha", Summary: "Per-character probability disclosure, as JSON or HTML",
+		Params: disclosureParams, Response: models.Disclosure{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+
+	// v2 user
+	{Method: http.MethodGet, Path: "/api/v2/user/info", Tag: "user", Summary: "Default user's balance, pity and tickets", Response: models.UserInfoResponse{}},
+	{Method: http.MethodGet, Path: "/api/v2/user/inventory", Tag: "user", Summary: "Default user's characters", Response: models.InventoryResponse{}},
+	{Method: http.MethodPost, Path: "/api/v2/user/add-currency", Tag: "user", Summary: "Purchase currency",
+		Request: models.AddCurrencyRequest{}, Response: models.CurrencyResponse{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden}},
+	{Method: http.MethodPost, Path: "/api/v2/user/register", Tag: "user", Summary: "Register a user in an age bracket",
+		Request: models.RegisterRequest{}, Response: models.UserInfoResponse{}, Errors: []int{http.StatusBadRequest, http.StatusConflict}},
+	{Method: http.MethodGet, Path: "/api/v2/user/limits", Tag: "user", Summary: "Spending limits and usage this month", Response: models.LimitsResponse{}},
+	{Method: http.MethodPut, Path: "/api/v2/user/limits", Tag: "user", Summary: "Set a self-imposed monthly limit; raising or removing it waits for the next reset",
+		Request: models.SetLimitRequest{}, Response: models.LimitsResponse{}, Errors: []int{http.StatusBadRequest}},
+	{Method: http.MethodGet, Path: "/api/v2/user/daily", Tag: "user", Summary: "Login calendar, streak and next daily reward", Response: models.DailyStatusResponse{}},
+
+	// v2 shop
+	{Method: http.MethodGet, Path: "/api/v2/shop", Tag: "shop", Summary: "Shop offers and the items they sell", Response: models.ShopResponse{}},
+	{Method: http.MethodPost, Path: "/api/v2/shop/buy", Tag: "shop", Summary: "Buy a shop offer with currency",
+		Request: models.ShopPurchaseRequest{}, Response: models.ShopPurchaseResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusPaymentRequired, http.StatusForbidden, http.StatusNotFound}},
+
+	// v2 missions
+	{Method: http.MethodGet, Path: "/api/v2/missions", Tag: "missions", Summary: "Progress on every mission and achievement", Response: models.MissionsResponse{}},
+	{Method: http.MethodPost, Path: "/api/v2/missions/:id/claim", Tag: "missions", Summary: "Claim a completed mission's reward",
+		Params: []Param{{Name: "id", In: "path", Type: ""}}, Response: models.MissionStatus{}, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+
+	// v2 season pass
+	{Method: http.MethodGet, Path: "/api/v2/season", Tag: "season", Summary: "Progress on the active season pass and the next season", Response: models.SeasonStatusResponse{}},
+	{Method: http.MethodPost, Path: "/api/v2/season/claim", Tag: "season", Summary: "Claim a reached tier's reward on the free or premium track",
+		Request: models.SeasonClaimRequest{}, Response: models.SeasonTierStatus{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict}},
+	{Method: http.MethodPost, Path: "/api/v2/season/premium", Tag: "season", Summary: "Unlock the active season's premium track with currency",
+		Response: models.SeasonStatusResponse{}, Errors: []int{http.StatusPaymentRequired, http.StatusForbidden, http.StatusConflict}},
+
+	// v2 fairness
+	{Method: http.MethodPost, Path: "/api/v2/fairness/verify", Tag: "fairness", Summary: "Recompute a pull from a revealed server seed",
+		Request: models.VerifyRequest{}, Response: models.VerifyResponse{}, Errors: []int{http.StatusBadRequest}},
+	{Method: http.MethodGet, Path: "/api/v2/fairness", Tag: "fairness", Summary: "Active server seed commitment",
+		Response: models.FairnessCommitment{}, Errors: []int{http.StatusConflict}},
+	{Method: http.MethodPost, Path: "/api/v2/fairness/client-seed", Tag: "fairness", Summary: "Change the client seed",
+		Request: models.ClientSeedRequest{}, Response: models.FairnessCommitment{}, Errors: []int{http.StatusBadRequest, http.StatusConflict}},
+	{Method: http.MethodPost, Path: "/api/v2/fairness/rotate", Tag: "fairness", Summary: "Reveal the server seed and commit to a new one",
+		Response: models.RevealedSeed{}, Errors: []int{http.StatusConflict}},
+}
+
+var calculatorParams = []Param{
+	bannerParam,
+	{Name: "target", In: "query", Type: "", Description: "Character ID or name, the banner's first featured character when omitted"},
+	{Name: "pity", In: "query", Type: 0, Description: "Pity count to start from, the user's when omitted"},
+	{Name: "guaranteed", In: "query", Type: false, Description: "Whether the next SSR is guaranteed to be featured"},
+	{Name: "pulls", In: "query", Type: 0, Description: "Number of pulls to chart, at most 100000, the pity threshold when omitted"},
+}
+
+var disclosureParams = []Param{
+	bannerParam,
+	{Name: "format", In: "query", Type: "", Description: "json (default) or html"},
+}
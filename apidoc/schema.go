@@ -0,0 +1,186 @@
+// Package apidoc describes the HTTP and WebSocket APIs as OpenAPI and
+// AsyncAPI documents, deriving every schema from the models it documents.
+package apidoc
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema (draft 2020-12), the dialect of OpenAPI 3.1 and
+// AsyncAPI 3
+type Schema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"` // A type name, or a list of them
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Dialect2020 identifies the JSON Schema draft 2020-12 meta-schema
+const Dialect2020 = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Registry generates schemas for Go types, collecting named struct types as
+// definitions referenced under a prefix such as "#/components/schemas/"
+type Registry struct {
+	prefix string
+	defs   map[string]*Schema
+}
+
+// NewRegistry creates a registry whose references start with prefix
+func NewRegistry(prefix string) *Registry {
+	return &Registry{
+		prefix: prefix,
+		defs:   make(map[string]*Schema),
+	}
+}
+
+// Definitions returns the schemas of the named types seen so far
+func (r *Registry) Definitions() map[string]*Schema {
+	return r.defs
+}
+
+// Schema returns the schema of v's type, a reference for named structs
+func (r *Registry) Schema(v interface{}) *Schema {
+	return r.schema(reflect.TypeOf(v))
+}
+
+func (r *Registry) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: float(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		// encoding/json writes nil slices as null
+		return &Schema{Type: []string{"array", "null"}, Items: r.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: []string{"object", "null"}, AdditionalProperties: r.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.object(t)
+		}
+		if _, ok := r.defs[t.Name()]; !ok {
+			r.defs[t.Name()] = nil // Placeholder for recursive types
+			r.defs[t.Name()] = r.object(t)
+		}
+		return &Schema{Ref: r.prefix + t.Name()}
+	}
+	return &Schema{} // interface{}: any value
+}
+
+// object describes a struct by its JSON fields. Fields of request types
+// (named ...Request) are required when bound with binding:"required"; fields
+// of other types are required unless omitempty.
+func (r *Registry) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	r.addFields(s, t, strings.HasSuffix(t.Name(), "Request"))
+	return s
+}
+
+func (r *Registry) addFields(s *Schema, t reflect.Type, request bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			r.addFields(s, field.Type, request)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		rules := bindingRules(field.Tag.Get("binding"))
+		prop := r.schema(field.Type)
+		applyRules(prop, rules)
+		s.Properties[name] = prop
+
+		_, bound := rules["required"]
+		omitEmpty := strings.Contains(opts, "omitempty")
+		if bound || (!request && !omitEmpty) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// bindingRules parses a binding tag, e.g. "required,gt=0", into rule parameters
+func bindingRules(tag string) map[string]string {
+	rules := make(map[string]string)
+	for _, rule := range strings.Split(tag, ",") {
+		if rule == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(rule, "=")
+		rules[name] = param
+	}
+	return rules
+}
+
+// applyRules carries validation rules over to a property's schema
+func applyRules(s *Schema, rules map[string]string) {
+	if param, ok := rules["gt"]; ok {
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			s.ExclusiveMinimum = float(n)
+		}
+	}
+	if param, ok := rules["gte"]; ok {
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			s.Minimum = float(n)
+		}
+	}
+	if param, ok := rules["max"]; ok && s.Type == "string" {
+		if n, err := strconv.Atoi(param); err == nil {
+			s.MaxLength = &n
+		}
+	}
+	if param, ok := rules["oneof"]; ok {
+		for _, value := range strings.Fields(param) {
+			s.Enum = append(s.Enum, value)
+		}
+	}
+}
+
+func float(n float64) *float64 {
+	return &n
+}
@@ -0,0 +1,96 @@
+// Package audit hashes and verifies the tamper-evident audit log.
+//
+// The log is a JSON Lines file of models.AuditEntry. Each entry's hash is the
+// SHA-256 of the previous entry's hash and the entry's own JSON encoding with
+// the hash field empty, so editing, removing or reordering an entry breaks
+// every hash after it. Entries cut from the end of the log leave a valid
+// chain, so truncation is not detected.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gacha/models"
+)
+
+// GenesisHash is the previous hash of the first entry
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// maxLineSize bounds a single entry when reading a log
+const maxLineSize = 1 << 20
+
+// ChainError reports the first entry at which a log fails verification
+type ChainError struct {
+	Line   int
+	Seq    uint64
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit log broken at line %d (seq %d): %s", e.Line, e.Seq, e.Reason)
+}
+
+// Hash computes an entry's hash from its fields and previous hash
+func Hash(entry models.AuditEntry) (string, error) {
+	entry.Hash = ""
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.New()
+	sum.Write([]byte(entry.PrevHash))
+	sum.Write(data)
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// Check verifies that entry correctly follows an entry with hash prevHash and
+// sequence number prevSeq
+func Check(entry models.AuditEntry, prevSeq uint64, prevHash string) string {
+	if entry.Seq != prevSeq+1 {
+		return fmt.Sprintf("sequence %d follows %d", entry.Seq, prevSeq)
+	}
+	if entry.PrevHash != prevHash {
+		return "previous hash does not match the preceding entry"
+	}
+	hash, err := Hash(entry)
+	if err != nil {
+		return err.Error()
+	}
+	if entry.Hash != hash {
+		return "hash does not match the entry's contents"
+	}
+	return ""
+}
+
+// Read parses and verifies a log, returning its entries. On a broken chain it
+// returns the entries before the break and a *ChainError.
+func Read(r io.Reader) ([]models.AuditEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var entries []models.AuditEntry
+	prevSeq, prevHash := uint64(0), GenesisHash
+	for line := 1; scanner.Scan(); line++ {
+		var entry models.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return entries, &ChainError{Line: line, Seq: prevSeq + 1, Reason: "malformed entry: " + err.Error()}
+		}
+		if reason := Check(entry, prevSeq, prevHash); reason != "" {
+			return entries, &ChainError{Line: line, Seq: entry.Seq, Reason: reason}
+		}
+
+		entries = append(entries, entry)
+		prevSeq, prevHash = entry.Seq, entry.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("read audit log: %w", err)
+	}
+
+	return entries, nil
+}
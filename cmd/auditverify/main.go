@@ -0,0 +1,50 @@
+// Command auditverify walks the audit log's hash chain and reports the first
+// entry that was altered, removed or reordered. Entries removed from the end
+// of the log leave an intact chain and are not reported.
+//
+// It exits with status 0 when the chain is intact and 1 when it is broken.
+//
+// Usage:
+//
+//	auditverify [-file audit.jsonl]
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gacha/audit"
+)
+
+func main() {
+	path := flag.String("file", "audit.jsonl", "audit log to verify")
+	flag.Parse()
+
+	file, err := os.Open(*path)
+	if err != nil {
+		log.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	entries, err := audit.Read(file)
+
+	var chainErr *audit.ChainError
+	if errors.As(err, &chainErr) {
+		fmt.Printf("BROKEN: %v\n", chainErr)
+		fmt.Printf("%d entries verified before the break\n", len(entries))
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("Failed to verify audit log: %v", err)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("OK: audit log is empty")
+		return
+	}
+	last := entries[len(entries)-1]
+	fmt.Printf("OK: %d entries verified, head %s at %s\n", len(entries), last.Hash, last.Time.Format("2006-01-02T15:04:05Z07:00"))
+}
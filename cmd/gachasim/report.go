@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// percentiles reported for every distribution
+var percentiles = []float64{10, 25, 50, 75, 90, 99}
+
+// Distribution summarises how many pulls users needed to reach a milestone
+type Distribution struct {
+	ReachedRate float64        `json:"reachedRate"` // Share of users who reached it within their budget
+	Mean        float64        `json:"mean"`        // Pulls, among users who reached it
+	Percentiles map[string]int `json:"percentiles"`
+	CostMean    float64        `json:"costMean"`
+	CostAt      map[string]int `json:"costPercentiles"`
+}
+
+// Report is the outcome of a simulation run
+type Report struct {
+	Banner          string             `json:"banner"`
+	Mode            string             `json:"mode"`
+	Users           int                `json:"users"`
+	PullsPerUser    int                `json:"pullsPerUser"`
+	Seed            uint64             `json:"seed"`
+	RarityRates     map[string]float64 `json:"rarityRates"`
+	PityHitRate     float64            `json:"pityHitRate"` // Share of SSRs granted by pity
+	DuplicateRates  map[string]float64 `json:"duplicateRates"`
+	FeaturedPerUser float64            `json:"featuredPerUser"`
+	CostPerFeatured float64            `json:"costPerFeatured"`
+	FirstSSR        Distribution       `json:"pullsToFirstSSR"`
+	FirstFeatured   Distribution       `json:"pullsToFirstFeatured"`
+}
+
+// buildReport derives rates and distributions from a tally
+func buildReport(p simParams, t *tally) Report {
+	// costOf is the currency spent by the time a user has made the given number of pulls
+	costOf := func(pulls int) int {
+		if p.Mode != modeTen {
+			return pulls * p.SingleCost
+		}
+		tens := p.PullsPerUser / 10
+		if pulls <= tens*10 {
+			return (pulls + 9) / 10 * p.TenCost
+		}
+		return tens*p.TenCost + (pulls-tens*10)*p.SingleCost
+	}
+
+	report := Report{
+		Banner:         p.Banner.ID,
+		Mode:           p.Mode,
+		Users:          t.users,
+		PullsPerUser:   p.PullsPerUser,
+		Seed:           p.Seed,
+		RarityRates:    make(map[string]float64),
+		DuplicateRates: make(map[string]float64),
+		FirstSSR:       distribution(t.firstSSR, costOf),
+		FirstFeatured:  distribution(t.firstFeatured, costOf),
+	}
+
+	for rarity, n := range t.byRarity {
+		key := rarityLabel(rarity)
+		report.RarityRates[key] = ratio(n, t.pulls)
+		report.DuplicateRates[key] = ratio(t.dupesByRarity[rarity], n)
+	}
+	report.PityHitRate = ratio(t.pitySSRs, t.byRarity[5])
+	report.FeaturedPerUser = ratio(t.featured, t.users)
+	if t.featured > 0 {
+		report.CostPerFeatured = float64(t.currency) / float64(t.featured)
+	}
+
+	return report
+}
+
+// distribution summarises a histogram of users by pulls, where index 0 counts
+// users who never reached the milestone
+func distribution(hist []int, costOf func(int) int) Distribution {
+	d := Distribution{
+		Percentiles: make(map[string]int),
+		CostAt:      make(map[string]int),
+	}
+
+	reached, sum, sumCost := 0, 0, 0
+	for pulls := 1; pulls < len(hist); pulls++ {
+		reached += hist[pulls]
+		sum += pulls * hist[pulls]
+		sumCost += costOf(pulls) * hist[pulls]
+	}
+	if total := reached + hist[0]; total > 0 {
+		d.ReachedRate = float64(reached) / float64(total)
+	}
+	if reached == 0 {
+		return d
+	}
+	d.Mean = float64(sum) / float64(reached)
+	d.CostMean = float64(sumCost) / float64(reached)
+
+	for _, pct := range percentiles {
+		rank := int(float64(reached)*pct/100 + 0.5)
+		if rank < 1 {
+			rank = 1
+		}
+		seen := 0
+		for pulls := 1; pulls < len(hist); pulls++ {
+			seen += hist[pulls]
+			if seen >= rank {
+				key := percentileLabel(pct)
+				d.Percentiles[key] = pulls
+				d.CostAt[key] = costOf(pulls)
+				break
+			}
+		}
+	}
+	return d
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+func rarityLabel(rarity int) string {
+	switch rarity {
+	case 5:
+		return "ssr"
+	case 4:
+		return "sr"
+	case 3:
+		return "r"
+	}
+	return strconv.Itoa(rarity) + "star"
+}
+
+func percentileLabel(pct float64) string {
+	return "p" + strconv.FormatFloat(pct, 'f', -1, 64)
+}
+
+// sortedKeys returns map keys in a stable order for printing
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// rows flattens the report into metric/value pairs for table and CSV output
+func (r Report) rows() [][2]string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+	rows := [][2]string{
+		{"banner", r.Banner},
+		{"mode", r.Mode},
+		{"users", strconv.Itoa(r.Users)},
+		{"pulls_per_user", strconv.Itoa(r.PullsPerUser)},
+		{"seed", strconv.FormatUint(r.Seed, 10)},
+	}
+	for _, k := range sortedKeys(r.RarityRates) {
+		rows = append(rows, [2]string{"rate_" + k, f(r.RarityRates[k])})
+	}
+	for _, k := range sortedKeys(r.DuplicateRates) {
+		rows = append(rows, [2]string{"duplicate_rate_" + k, f(r.DuplicateRates[k])})
+	}
+	rows = append(rows,
+		[2]string{"pity_hit_rate", f(r.PityHitRate)},
+		[2]string{"featured_per_user", f(r.FeaturedPerUser)},
+		[2]string{"cost_per_featured", f(r.CostPerFeatured)},
+	)
+	for _, d := range []struct {
+		name string
+		dist Distribution
+	}{{"first_ssr", r.FirstSSR}, {"first_featured", r.FirstFeatured}} {
+		rows = append(rows,
+			[2]string{d.name + "_reached_rate", f(d.dist.ReachedRate)},
+			[2]string{d.name + "_pulls_mean", f(d.dist.Mean)},
+			[2]string{d.name + "_cost_mean", f(d.dist.CostMean)},
+		)
+		for _, pct := range percentiles {
+			key := percentileLabel(pct)
+			rows = append(rows,
+				[2]string{d.name + "_pulls_" + key, strconv.Itoa(d.dist.Percentiles[key])},
+				[2]string{d.name + "_cost_" + key, strconv.Itoa(d.dist.CostAt[key])},
+			)
+		}
+	}
+	return rows
+}
+
+// writeReport writes the report in the requested format
+func writeReport(w io.Writer, r Report, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"metric", "value"})
+		for _, row := range r.rows() {
+			cw.Write(row[:])
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, row := range r.rows() {
+			fmt.Fprintf(tw, "%s\t%s\n", row[0], row[1])
+		}
+		return tw.Flush()
+	}
+
+	return fmt.Errorf("unknown format %q", format)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"gacha/engine"
+	"gacha/models"
+	"gacha/services"
+)
+
+// Pull modes
+const (
+	modeSingle = "single"
+	modeTen    = "ten"
+)
+
+// simParams describes a simulation run
+type simParams struct {
+	Banner        models.Banner
+	PityThreshold int
+	SingleCost    int
+	TenCost       int
+	Users         int
+	PullsPerUser  int
+	Mode          string
+	Seed          uint64
+	Workers       int
+}
+
+// tally accumulates outcomes across simulated users
+type tally struct {
+	users         int
+	pulls         int
+	currency      int
+	byRarity      map[int]int
+	dupesByRarity map[int]int
+	pitySSRs      int
+	featured      int
+	firstSSR      []int // Users by pulls to their first SSR, index 0 for never
+	firstFeatured []int // Users by pulls to their first featured character, index 0 for never
+}
+
+func newTally(pullsPerUser int) *tally {
+	return &tally{
+		byRarity:      make(map[int]int),
+		dupesByRarity: make(map[int]int),
+		firstSSR:      make([]int, pullsPerUser+1),
+		firstFeatured: make([]int, pullsPerUser+1),
+	}
+}
+
+// merge adds another tally into t
+func (t *tally) merge(o *tally) {
+	t.users += o.users
+	t.pulls += o.pulls
+	t.currency += o.currency
+	t.pitySSRs += o.pitySSRs
+	t.featured += o.featured
+	for rarity, n := range o.byRarity {
+		t.byRarity[rarity] += n
+	}
+	for rarity, n := range o.dupesByRarity {
+		t.dupesByRarity[rarity] += n
+	}
+	for i := range t.firstSSR {
+		t.firstSSR[i] += o.firstSSR[i]
+		t.firstFeatured[i] += o.firstFeatured[i]
+	}
+}
+
+// simulate runs every simulated user through the server's gacha service and
+// aggregates their outcomes. Each user draws from its own stream derived from
+// the seed, so results do not depend on the number of workers.
+func simulate(p simParams) *tally {
+	banner := services.NewGachaServiceWithPool(p.Banner.Characters, p.PityThreshold, engine.NewSeededSource(p.Seed))
+
+	results := make(chan *tally, p.Workers)
+	var wg sync.WaitGroup
+	for w := 0; w < p.Workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			t := newTally(p.PullsPerUser)
+			for i := worker; i < p.Users; i += p.Workers {
+				random := engine.NewSeededSource(splitmix64(p.Seed + uint64(i)))
+				simulateUser(banner.WithSource(random), p, t)
+			}
+			results <- t
+		}(w)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	total := newTally(p.PullsPerUser)
+	for t := range results {
+		total.merge(t)
+	}
+	return total
+}
+
+// simulateUser spends one user's pull budget and records its outcomes
+func simulateUser(pulls *services.GachaService, p simParams, t *tally) {
+	ctx := context.Background()
+	user := &models.User{Inventory: []models.Character{}}
+	pulled, firstSSR, firstFeatured := 0, 0, 0
+	pity := 0 // Mirrors the engine's pity counter to spot pity-granted SSRs
+
+	record := func(char models.Character) {
+		pulled++
+		pity++
+		t.pulls++
+		t.byRarity[char.Rarity]++
+		if !user.AddCharacter(char) {
+			t.dupesByRarity[char.Rarity]++
+		}
+		if char.Rarity == 5 {
+			if pity >= p.PityThreshold {
+				t.pitySSRs++
+			}
+			pity = 0
+			if firstSSR == 0 {
+				firstSSR = pulled
+			}
+		}
+		if p.Banner.IsFeatured(char.ID) {
+			t.featured++
+			if firstFeatured == 0 {
+				firstFeatured = pulled
+			}
+		}
+	}
+
+	for pulled < p.PullsPerUser {
+		if p.Mode == modeTen && p.PullsPerUser-pulled >= 10 {
+			t.currency += p.TenCost
+			for _, char := range pulls.PerformTenPull(ctx, user) {
+				record(char)
+			}
+			continue
+		}
+
+		t.currency += p.SingleCost
+		record(pulls.PerformSinglePull(ctx, user))
+	}
+
+	t.users++
+	t.firstSSR[firstSSR]++
+	t.firstFeatured[firstFeatured]++
+}
+
+// splitmix64 scrambles a seed so that neighbouring users get unrelated streams
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
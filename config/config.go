@@ -1,13 +1,32 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"gacha/models"
 )
 
 // Config holds application configuration
 type Config struct {
-	Server ServerConfig
-	Gacha  GachaConfig
+	Server    ServerConfig
+	Gacha     GachaConfig
+	RateLimit RateLimitConfig
+	Limits    LimitsConfig
+	Daily     DailyConfig
+	Season    SeasonConfig
+	Log       LogConfig
+	Tracing   TracingConfig
+	Audit     AuditConfig
+	Admin     AdminConfig
 }
 
 // ServerConfig holds server configuration
@@ -17,35 +36,329 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+	DrainDelay      time.Duration // How long readiness reports not-ready before the server stops accepting requests
 }
 
 // GachaConfig holds gacha system configuration
 type GachaConfig struct {
 	SinglePullCost int
 	TenPullCost    int
+	MaxPullCount   int // Most pulls one multi pull request may ask for
 	PityThreshold  int
 	SSRRate        float64
 	SRRate         float64
 	RRate          float64
+	Seed           uint64 // Non-zero replays pulls deterministically, for reproductions
+	ProvablyFair   bool   // Derive pulls from committed server seeds, off unless GACHA_PROVABLY_FAIR turns it on; exclusive with Seed
+}
+
+// RateLimitConfig holds WebSocket rate limiting and backpressure configuration
+type RateLimitConfig struct {
+	PerConnection  map[string]RateLimit // Keyed by message type, "*" applies to other types
+	PerUser        map[string]RateLimit // Shared by all connections of a user
+	SendBufferSize int
+	SendTimeout    time.Duration // How long a saturated client may take to flush its queue before disconnecting
+}
+
+// RateLimit describes a token bucket refilled at Rate tokens per second up to Burst
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// LimitsConfig holds monthly spending limit configuration
+type LimitsConfig struct {
+	Timezone    string         // IANA timezone whose calendar months reset the limits
+	BracketCaps map[string]int // Monthly cap per age bracket, 0 for unlimited
+}
+
+// DailyConfig holds daily login reward configuration
+type DailyConfig struct {
+	Timezone string          // IANA timezone whose calendar days reset the rewards and missions
+	Rewards  []models.Reward // Granted by streak day, repeating after the last; empty disables rewards
+}
+
+// SeasonConfig holds season pass configuration
+type SeasonConfig struct {
+	XP      models.SeasonXP
+	Seasons []models.Season // Scheduled seasons, which must not overlap; when empty, QuarterlySeason runs every calendar quarter
+}
+
+// LogConfig holds logging configuration
+type LogConfig struct {
+	Level  string // debug, info, warn or error
+	Format string // json or text
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Exporter    string  // none, stdout or otlp
+	Endpoint    string  // OTLP/HTTP collector address, host:port
+	Insecure    bool    // Send OTLP over plain HTTP, for a local collector
+	SampleRatio float64 // Fraction of new traces sampled, 0 to 1
+	ServiceName string
+}
+
+// AuditConfig holds audit log configuration
+type AuditConfig struct {
+	Path string // JSON Lines file of the audit chain, empty to keep it in memory
+}
+
+// AdminConfig holds admin API configuration
+type AdminConfig struct {
+	// Token is required in X-Admin-Token; admin routes are disabled when empty.
+	// It is read from GACHA_ADMIN_TOKEN only, keeping it out of config files
+	// and the config hash.
+	Token string `json:"-"`
 }
 
 // LoadConfig loads configuration (can be extended to read from file/env)
 func LoadConfig() *Config {
-	return &Config{
+	cfg := &Config{
 		Server: ServerConfig{
 			Port:            ":8080",
 			AllowedOrigins:  []string{"*"},
 			ReadTimeout:     15 * time.Second,
 			WriteTimeout:    15 * time.Second,
 			ShutdownTimeout: 10 * time.Second,
+			DrainDelay:      5 * time.Second,
 		},
 		Gacha: GachaConfig{
 			SinglePullCost: 160,
 			TenPullCost:    1600,
+			MaxPullCount:   100,
 			PityThreshold:  90,
 			SSRRate:        0.02, // 2%
 			SRRate:         0.10, // 10%
 			RRate:          0.88, // 88%
 		},
+		RateLimit: RateLimitConfig{
+			PerConnection: map[string]RateLimit{
+				"*":           {Rate: 20, Burst: 40},
+				"single_pull": {Rate: 2, Burst: 5},
+				"ten_pull":    {Rate: 1, Burst: 3},
+				"pull":        {Rate: 1, Burst: 3},
+			},
+			PerUser: map[string]RateLimit{
+				"*":           {Rate: 50, Burst: 100},
+				"single_pull": {Rate: 5, Burst: 10},
+				"ten_pull":    {Rate: 2, Burst: 5},
+				"pull":        {Rate: 2, Burst: 5},
+			},
+			SendBufferSize: 256,
+			SendTimeout:    2 * time.Second,
+		},
+		Limits: LimitsConfig{
+			Timezone: "UTC",
+			BracketCaps: map[string]int{
+				"under_13": 8000,
+				"13_15":    16000,
+				"16_17":    32000,
+				"adult":    0,
+			},
+		},
+		Daily: DailyConfig{
+			Timezone: "UTC",
+			Rewards: []models.Reward{
+				{Currency: 100},
+				{Currency: 100},
+				{Currency: 150},
+				{Items: map[string]int{models.ItemGenericTicket: 1}},
+				{Currency: 200},
+				{Currency: 200},
+				{Currency: 300, Items: map[string]int{models.ItemGenericTicket: 2}},
+			},
+		},
+		Season: SeasonConfig{
+			XP: models.SeasonXP{Pull: 10, Login: 100, Mission: 200},
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			SampleRatio: 1,
+			ServiceName: "gacha",
+		},
+		Audit: AuditConfig{
+			Path: "audit.jsonl",
+		},
+	}
+
+	// Production deployments run Gin in release mode and ship JSON logs
+	if os.Getenv("GIN_MODE") == "release" {
+		cfg.Log.Format = "json"
+	}
+	if level := os.Getenv("GACHA_LOG_LEVEL"); level != "" {
+		cfg.Log.Level = level
+	}
+	if format := os.Getenv("GACHA_LOG_FORMAT"); format != "" {
+		cfg.Log.Format = format
+	}
+	if exporter := os.Getenv("GACHA_TRACE_EXPORTER"); exporter != "" {
+		cfg.Tracing.Exporter = exporter
+	}
+	if endpoint := os.Getenv("GACHA_TRACE_ENDPOINT"); endpoint != "" {
+		cfg.Tracing.Endpoint = endpoint
+	}
+	if path, ok := os.LookupEnv("GACHA_AUDIT_PATH"); ok {
+		cfg.Audit.Path = path
+	}
+	cfg.Admin.Token = os.Getenv("GACHA_ADMIN_TOKEN")
+
+	// A seed only drives pulls outside provably fair mode, so Validate
+	// rejects setting both
+	if seed, err := strconv.ParseUint(os.Getenv("GACHA_SEED"), 10, 64); err == nil {
+		cfg.Gacha.Seed = seed
+	}
+	if fair, err := strconv.ParseBool(os.Getenv("GACHA_PROVABLY_FAIR")); err == nil {
+		cfg.Gacha.ProvablyFair = fair
+	}
+
+	return cfg
+}
+
+// LoadConfigFile loads configuration from a JSON file, keeping LoadConfig's
+// values for anything the file omits
+func LoadConfigFile(path string) (*Config, error) {
+	cfg := LoadConfig()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Validate checks that the configuration is usable
+func (c *Config) Validate() error {
+	if c.Gacha.SinglePullCost <= 0 || c.Gacha.TenPullCost <= 0 {
+		return errors.New("pull costs must be positive")
+	}
+	if c.Gacha.MaxPullCount <= 0 || c.Gacha.MaxPullCount > models.MaxPullCount {
+		return fmt.Errorf("max pull count must be between 1 and %d", models.MaxPullCount)
+	}
+	if c.Gacha.PityThreshold <= 0 {
+		return errors.New("pity threshold must be positive")
+	}
+	if c.Gacha.Seed != 0 && c.Gacha.ProvablyFair {
+		return errors.New("a deterministic seed and provably fair mode are mutually exclusive")
+	}
+	if sum := c.Gacha.SSRRate + c.Gacha.SRRate + c.Gacha.RRate; math.Abs(sum-1) > 1e-9 {
+		return fmt.Errorf("rarity rates sum to %f, want 1", sum)
+	}
+	if _, err := time.LoadLocation(c.Limits.Timezone); err != nil {
+		return fmt.Errorf("limits timezone: %w", err)
+	}
+	if _, err := time.LoadLocation(c.Daily.Timezone); err != nil {
+		return fmt.Errorf("daily timezone: %w", err)
+	}
+	for i, reward := range c.Daily.Rewards {
+		if err := validateReward(reward); err != nil {
+			return fmt.Errorf("daily reward %d: %w", i+1, err)
+		}
+	}
+	if err := c.Season.validate(); err != nil {
+		return fmt.Errorf("season: %w", err)
+	}
+	return nil
+}
+
+// validate checks that seasons are well formed and scheduled one at a time
+func (c *SeasonConfig) validate() error {
+	seen := make(map[string]bool)
+	for i, season := range c.Seasons {
+		switch {
+		case season.ID == "" || seen[season.ID]:
+			return fmt.Errorf("season %d: missing or duplicate ID %q", i+1, season.ID)
+		case !season.End.After(season.Start):
+			return fmt.Errorf("season %s ends before it starts", season.ID)
+		case season.XPPerTier <= 0:
+			return fmt.Errorf("season %s: XP per tier must be positive", season.ID)
+		case season.PremiumPrice < 0:
+			return fmt.Errorf("season %s: premium price must not be negative", season.ID)
+		case len(season.Tiers) == 0:
+			return fmt.Errorf("season %s has no tiers", season.ID)
+		}
+		seen[season.ID] = true
+
+		for tier, rewards := range season.Tiers {
+			for _, reward := range []models.Reward{rewards.Free, rewards.Premium} {
+				if err := validateReward(reward); err != nil {
+					return fmt.Errorf("season %s tier %d: %w", season.ID, tier+1, err)
+				}
+			}
+		}
+		for _, other := range c.Seasons[:i] {
+			if season.Start.Before(other.End) && other.Start.Before(season.End) {
+				return fmt.Errorf("seasons %s and %s overlap", other.ID, season.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// validateReward checks that a reward grants known items in positive quantities
+func validateReward(reward models.Reward) error {
+	if reward.Currency < 0 {
+		return errors.New("negative currency")
+	}
+	for itemID, quantity := range reward.Items {
+		if _, ok := models.FindItem(itemID); !ok || quantity <= 0 {
+			return fmt.Errorf("invalid item %q quantity %d", itemID, quantity)
+		}
+	}
+	return nil
+}
+
+// QuarterlySeason returns the default season of the calendar quarter in UTC
+// containing t, which runs when no seasons are scheduled. Seasons are named
+// after their quarter, so a restart within a quarter keeps the same season.
+func QuarterlySeason(t time.Time) models.Season {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	quarter := fmt.Sprintf("%d Q%d", start.Year(), (start.Month()+2)/3)
+	return models.Season{
+		ID:           "season-" + strings.ToLower(strings.ReplaceAll(quarter, " ", "-")),
+		Name:         "Season " + quarter,
+		Start:        start,
+		End:          start.AddDate(0, 3, 0),
+		XPPerTier:    1000,
+		PremiumPrice: 1000,
+		Tiers:        defaultSeasonTiers(10),
+	}
+}
+
+// defaultSeasonTiers returns tiers granting currency on the free track and
+// pull tickets on the premium track, more on every fifth tier
+func defaultSeasonTiers(count int) []models.SeasonTier {
+	tiers := make([]models.SeasonTier, count)
+	for i := range tiers {
+		tiers[i] = models.SeasonTier{
+			Free:    models.Reward{Currency: 100},
+			Premium: models.Reward{Items: map[string]int{models.ItemGenericTicket: 1}},
+		}
+		if (i+1)%5 == 0 {
+			tiers[i].Free.Items = map[string]int{models.ItemGenericTicket: 1}
+			tiers[i].Premium.Items[models.ItemGenericTicket] = 3
+		}
+	}
+	return tiers
+}
+
+// Hash returns a short fingerprint of the configuration, to tell deployments apart
+func (c *Config) Hash() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
 	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
 }
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"gacha/models"
+)
+
+func TestQuarterlySeasonFollowsQuarters(t *testing.T) {
+	want := []struct {
+		at         time.Time
+		id         string
+		start, end time.Time
+	}{
+		{time.Date(2027, 2, 14, 12, 0, 0, 0, time.UTC), "season-2027-q1", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC), "season-2027-q2", time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2027, 12, 31, 23, 59, 0, 0, time.UTC), "season-2027-q4", time.Date(2027, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, w := range want {
+		if s := QuarterlySeason(w.at); s.ID != w.id || !s.Start.Equal(w.start) || !s.End.Equal(w.end) {
+			t.Errorf("season at %v is %s from %v to %v, want %s from %v to %v", w.at, s.ID, s.Start, s.End, w.id, w.start, w.end)
+		}
+	}
+
+	// The default seasons are valid
+	cfg := SeasonConfig{Seasons: []models.Season{QuarterlySeason(time.Now())}}
+	if err := cfg.validate(); err != nil {
+		t.Errorf("quarterly season: %v", err)
+	}
+}
+
+func TestSeasonValidateRejectsNegativePremiumPrice(t *testing.T) {
+	cfg := SeasonConfig{Seasons: []models.Season{QuarterlySeason(time.Now())}}
+	cfg.Seasons[0].PremiumPrice = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("negative premium price accepted")
+	}
+
+	cfg.Seasons[0].PremiumPrice = 0
+	if err := cfg.validate(); err != nil {
+		t.Errorf("free premium pass rejected: %v", err)
+	}
+}
+
+func TestProvablyFairIsOptIn(t *testing.T) {
+	if LoadConfig().Gacha.ProvablyFair {
+		t.Error("provably fair mode on by default")
+	}
+
+	t.Setenv("GACHA_PROVABLY_FAIR", "true")
+	if !LoadConfig().Gacha.ProvablyFair {
+		t.Error("GACHA_PROVABLY_FAIR=true left provably fair mode off")
+	}
+
+	t.Setenv("GACHA_SEED", "42")
+	if err := LoadConfig().Validate(); err == nil {
+		t.Error("seed accepted in provably fair mode")
+	}
+}
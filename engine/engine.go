@@ -0,0 +1,162 @@
+// Package engine implements the pull algorithm and the random sources that
+// drive it. It depends on nothing but the models, so that the standalone
+// fairness verifier recomputes pulls with exactly the server's algorithm
+// without importing the server's services.
+package engine
+
+import "gacha/models"
+
+// DefaultPityThreshold is the number of pulls that guarantees an SSR
+const DefaultPityThreshold = 90
+
+// PullObserver is notified of pull mechanics, such as for metrics
+type PullObserver interface {
+	PityTriggered()
+}
+
+// Engine draws characters from a weighted pool, applying pity
+type Engine struct {
+	pool          []models.Character
+	pityThreshold int
+	random        RandomSource
+	observer      PullObserver
+}
+
+// New creates an engine over a character pool and pity threshold drawing
+// from the given random source
+func New(pool []models.Character, pityThreshold int, random RandomSource) *Engine {
+	return &Engine{
+		pool:          pool,
+		pityThreshold: pityThreshold,
+		random:        random,
+	}
+}
+
+// WithSource returns a copy of the engine that draws from a different random source
+func (e *Engine) WithSource(random RandomSource) *Engine {
+	return &Engine{
+		pool:          e.pool,
+		pityThreshold: e.pityThreshold,
+		random:        random,
+		observer:      e.observer,
+	}
+}
+
+// SetObserver sets the observer notified of pull mechanics
+func (e *Engine) SetObserver(observer PullObserver) {
+	e.observer = observer
+}
+
+// Single draws one character
+func (e *Engine) Single(user *models.User) models.Character {
+	return e.pull(user)
+}
+
+// Ten draws ten characters with a guaranteed SR
+func (e *Engine) Ten(user *models.User) []models.Character {
+	var characters []models.Character
+	hasSR := false
+
+	for i := 0; i < 10; i++ {
+		char := e.pull(user)
+
+		// Last pull: force SR if no SR+ obtained, never replacing an SSR
+		if i == 9 && !hasSR && char.Rarity < 4 {
+			char = e.byRarity(4)
+		}
+
+		if char.Rarity >= 4 {
+			hasSR = true
+		}
+
+		characters = append(characters, char)
+	}
+	return characters
+}
+
+// Multi draws up to count characters one at a time, stopping after the
+// first that matches stopOn
+func (e *Engine) Multi(user *models.User, count int, stopOn *models.StopCondition) []models.Character {
+	characters := make([]models.Character, 0, count)
+	for len(characters) < count {
+		char := e.pull(user)
+		characters = append(characters, char)
+		if stopOn.Matches(char) {
+			break
+		}
+	}
+	return characters
+}
+
+// pull draws one character, applying and updating the user's pity
+func (e *Engine) pull(user *models.User) models.Character {
+	user.IncrementPity()
+
+	// Pity system: guaranteed SSR at the pity threshold
+	if user.PityCount >= e.pityThreshold {
+		user.ResetPity()
+		if e.observer != nil {
+			e.observer.PityTriggered()
+		}
+		return e.byRarity(5)
+	}
+
+	// Normal gacha logic
+	totalRate := 0.0
+	for _, char := range e.pool {
+		totalRate += char.Rate
+	}
+
+	roll := e.random.Float64() * totalRate
+	currentRate := 0.0
+
+	for _, char := range e.pool {
+		currentRate += char.Rate
+		if roll <= currentRate {
+			if char.Rarity == 5 {
+				user.ResetPity() // Reset pity when SSR obtained
+			}
+			return char
+		}
+	}
+
+	return e.pool[len(e.pool)-1]
+}
+
+// Pool returns the character pool
+func (e *Engine) Pool() []models.Character {
+	return e.pool
+}
+
+// PityThreshold returns the number of pulls that guarantees an SSR
+func (e *Engine) PityThreshold() int {
+	return e.pityThreshold
+}
+
+// RarityRates returns the base per-pull probability of each rarity from the pool weights
+func (e *Engine) RarityRates() map[int]float64 {
+	totalRate := 0.0
+	for _, char := range e.pool {
+		totalRate += char.Rate
+	}
+
+	rates := make(map[int]float64)
+	for _, char := range e.pool {
+		rates[char.Rarity] += char.Rate / totalRate
+	}
+	return rates
+}
+
+// byRarity draws a random character of a specific rarity
+func (e *Engine) byRarity(rarity int) models.Character {
+	var filtered []models.Character
+	for _, char := range e.pool {
+		if char.Rarity == rarity {
+			filtered = append(filtered, char)
+		}
+	}
+	if len(filtered) == 0 {
+		return e.pool[0]
+	}
+	return filtered[e.random.IntN(len(filtered))]
+}
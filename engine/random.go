@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+)
+
+// RandomSource provides the random numbers that drive pulls
+type RandomSource interface {
+	// Float64 returns a number in [0.0, 1.0)
+	Float64() float64
+	// IntN returns a number in [0, n)
+	IntN(n int) int
+}
+
+// lockedRand makes a *rand.Rand safe for concurrent use
+type lockedRand struct {
+	rng *rand.Rand
+	mu  sync.Mutex
+}
+
+func (r *lockedRand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Float64()
+}
+
+func (r *lockedRand) IntN(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.IntN(n)
+}
+
+// cryptoSource reads from the operating system's CSPRNG
+type cryptoSource struct{}
+
+func (cryptoSource) Uint64() uint64 {
+	var buf [8]byte
+	if _, err := crand.Read(buf[:]); err != nil {
+		panic(err)
+	}
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// NewCryptoSource creates a cryptographically secure random source for production
+func NewCryptoSource() RandomSource {
+	return &lockedRand{rng: rand.New(cryptoSource{})}
+}
+
+// NewSeededSource creates a deterministic random source that replays the
+// same sequence for the same seed
+func NewSeededSource(seed uint64) RandomSource {
+	return &lockedRand{rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// hmacSource derives a provably fair stream from a committed server seed.
+// The n-th number is the first 8 bytes, big-endian, of
+// HMAC-SHA256(serverSeed, "clientSeed:nonce:n").
+type hmacSource struct {
+	serverSeed string
+	clientSeed string
+	nonce      uint64
+	round      uint64
+}
+
+// NewHMACSource creates the random source for one provably fair pull
+func NewHMACSource(serverSeed, clientSeed string, nonce uint64) RandomSource {
+	return &hmacSource{
+		serverSeed: serverSeed,
+		clientSeed: clientSeed,
+		nonce:      nonce,
+	}
+}
+
+func (s *hmacSource) uint64() uint64 {
+	mac := hmac.New(sha256.New, []byte(s.serverSeed))
+	fmt.Fprintf(mac, "%s:%d:%d", s.clientSeed, s.nonce, s.round)
+	s.round++
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
+
+func (s *hmacSource) Float64() float64 {
+	return float64(s.uint64()>>11) / (1 << 53)
+}
+
+func (s *hmacSource) IntN(n int) int {
+	return int(s.Float64() * float64(n))
+}
+
+// HashServerSeed returns the commitment published for a server seed
+func HashServerSeed(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
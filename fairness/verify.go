@@ -0,0 +1,102 @@
+// Package fairness verifies provably fair pulls.
+//
+// Each pull commits to a server seed by publishing its SHA-256 hash. Once the
+// seed is rotated and revealed, anyone can recompute the pull by running the
+// gacha engine against the HMAC-SHA256 stream derived from the server seed,
+// client seed and nonce recorded in the pull's proof.
+package fairness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gacha/engine"
+	"gacha/models"
+)
+
+// Errors returned for pulls that cannot be recomputed
+var (
+	ErrSeedMismatch  = errors.New("server seed does not match its hash")
+	ErrUnknownBanner = errors.New("unknown banner")
+)
+
+// Verify recomputes the characters of a provably fair pull
+func Verify(ctx context.Context, req models.VerifyRequest) (*models.VerifyResponse, error) {
+	hash := engine.HashServerSeed(req.ServerSeed)
+	if req.ServerSeedHash != "" && req.ServerSeedHash != hash {
+		return nil, ErrSeedMismatch
+	}
+
+	// The engine is rebuilt as the server configured it when pulling
+	bannerID, pityThreshold := req.BannerID, req.PityThreshold
+	if bannerID == "" {
+		bannerID = models.DefaultBanner().ID
+	}
+	switch {
+	case pityThreshold < 0:
+		return nil, errors.New("pity threshold must be positive")
+	case pityThreshold == 0:
+		pityThreshold = engine.DefaultPityThreshold
+	}
+	banner, ok := models.FindBanner(bannerID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownBanner, bannerID)
+	}
+	pulls := engine.New(banner.Characters, pityThreshold, engine.NewHMACSource(req.ServerSeed, req.ClientSeed, req.Nonce))
+	user := &models.User{PityCount: req.PityBefore}
+
+	var characters []models.Character
+	switch req.PullType {
+	case models.PullTypeSingle:
+		characters = []models.Character{pulls.Single(user)}
+	case models.PullTypeTen:
+		characters = pulls.Ten(user)
+	case models.PullTypeMulti:
+		if req.Count < 1 || req.Count > models.MaxPullCount {
+			return nil, fmt.Errorf("multi pull count must be between 1 and %d", models.MaxPullCount)
+		}
+		characters = pulls.Multi(user, req.Count, req.StopOn)
+	default:
+		return nil, fmt.Errorf("unknown pull type %q", req.PullType)
+	}
+
+	return &models.VerifyResponse{
+		ServerSeedHash: hash,
+		Characters:     characters,
+		PityAfter:      user.PityCount,
+	}, nil
+}
+
+// VerifyResult checks that a pull result matches what its revealed server seed produces
+func VerifyResult(ctx context.Context, result models.GachaResult, serverSeed string) error {
+	if result.Proof == nil {
+		return errors.New("result has no proof")
+	}
+
+	recomputed, err := Verify(ctx, models.VerifyRequest{
+		ServerSeed:     serverSeed,
+		ServerSeedHash: result.Proof.ServerSeedHash,
+		ClientSeed:     result.Proof.ClientSeed,
+		Nonce:          result.Proof.Nonce,
+		PityBefore:     result.Proof.PityBefore,
+		PityThreshold:  result.Proof.PityThreshold,
+		BannerID:       result.Proof.BannerID,
+		PullType:       result.Proof.PullType,
+		Count:          result.Proof.Count,
+		StopOn:         result.Proof.StopOn,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(recomputed.Characters) != len(result.Characters) {
+		return fmt.Errorf("expected %d characters, result has %d", len(recomputed.Characters), len(result.Characters))
+	}
+	for i, char := range recomputed.Characters {
+		if result.Characters[i].ID != char.ID {
+			return fmt.Errorf("pull %d: expected character %d, result has %d", i+1, char.ID, result.Characters[i].ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package fairness
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gacha/config"
+	"gacha/engine"
+	"gacha/internal/testenv"
+	"gacha/models"
+)
+
+// newEnv returns services in provably fair mode with a pity threshold
+func newEnv(t *testing.T, pityThreshold int) *testenv.Env {
+	t.Helper()
+	cfg := config.LoadConfig()
+	cfg.Gacha.ProvablyFair = true
+	cfg.Gacha.PityThreshold = pityThreshold
+	return testenv.New(t, cfg)
+}
+
+func TestVerifyResultReplaysMultiPull(t *testing.T) {
+	env := newEnv(t, engine.DefaultPityThreshold)
+	economy, fairness := env.Economy, env.Fairness
+
+	user := &models.User{Username: "fair", Currency: 1 << 30}
+	result, err := economy.PullMany(context.Background(), user, models.PullRequest{
+		Count:  50,
+		StopOn: &models.StopCondition{Rarity: 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Proof == nil || result.Proof.Count != 50 || result.Proof.StopOn == nil {
+		t.Fatalf("proof %+v does not record the request", result.Proof)
+	}
+
+	seed := fairness.Rotate(user.Username).ServerSeed
+	if err := VerifyResult(context.Background(), result, seed); err != nil {
+		t.Errorf("verify: %v", err)
+	}
+
+	// Replaying an early stop without its condition pulls past it
+	if result.StoppedEarly {
+		result.Proof.StopOn = nil
+		if err := VerifyResult(context.Background(), result, seed); err == nil {
+			t.Error("verify ignored the stop condition")
+		}
+	}
+}
+
+func TestVerifyResultUsesRecordedPityThreshold(t *testing.T) {
+	env := newEnv(t, 3)
+	economy, fairness := env.Economy, env.Fairness
+
+	user := &models.User{Username: "pity", Currency: 1 << 30}
+	result, err := economy.PullMany(context.Background(), user, models.PullRequest{Count: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Proof.PityThreshold != 3 || result.Proof.BannerID != models.DefaultBanner().ID {
+		t.Fatalf("proof %+v does not record the engine", result.Proof)
+	}
+
+	seed := fairness.Rotate(user.Username).ServerSeed
+	if err := VerifyResult(context.Background(), result, seed); err != nil {
+		t.Errorf("verify: %v", err)
+	}
+
+	// The default threshold never triggers pity within 50 pulls of a threshold of 3
+	result.Proof.PityThreshold = 0
+	if err := VerifyResult(context.Background(), result, seed); err == nil {
+		t.Error("verify ignored the pity threshold")
+	}
+
+	result.Proof.PityThreshold = 3
+	result.Proof.BannerID = "missing"
+	if err := VerifyResult(context.Background(), result, seed); !errors.Is(err, ErrUnknownBanner) {
+		t.Errorf("verify of an unknown banner returned %v", err)
+	}
+}
+
+func TestVerifyRejectsOversizedMultiPull(t *testing.T) {
+	for _, count := range []int{0, models.MaxPullCount + 1, 1 << 40} {
+		_, err := Verify(context.Background(), models.VerifyRequest{
+			ServerSeed: "seed",
+			PullType:   models.PullTypeMulti,
+			Count:      count,
+		})
+		if err == nil {
+			t.Errorf("verify of %d pulls succeeded", count)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gacha/config"
+	"gacha/models"
+	"gacha/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Admin request headers
+const (
+	AdminTokenHeader = "X-Admin-Token"
+	AdminActorHeader = "X-Admin-Actor" // Name of the admin acting, recorded in the audit log
+)
+
+// adminActorKey stores the acting admin in the Gin context
+const adminActorKey = "adminActor"
+
+// AdminHandler handles admin requests: currency and item grants, refunds,
+// user changes and audit log queries
+type AdminHandler struct {
+	userService    *services.UserService
+	limitService   *services.LimitService
+	auditService   *services.AuditService
+	economyService *services.EconomyService
+	adminConfig    config.AdminConfig
+	logger         *slog.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(userService *services.UserService, limitService *services.LimitService, auditService *services.AuditService, economyService *services.EconomyService, adminConfig config.AdminConfig, logger *slog.Logger) *AdminHandler {
+	return &AdminHandler{
+		userService:    userService,
+		limitService:   limitService,
+		auditService:   auditService,
+		economyService: economyService,
+		adminConfig:    adminConfig,
+		logger:         logger,
+	}
+}
+
+// RequireAdmin rejects requests without the configured admin token and
+// records the acting admin for the audit log
+func (h *AdminHandler) RequireAdmin(c *gin.Context) {
+	token := c.GetHeader(AdminTokenHeader)
+	if h.adminConfig.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminConfig.Token)) != 1 {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access denied"})
+		return
+	}
+
+	actor := c.GetHeader(AdminActorHeader)
+	if actor == "" {
+		actor = "admin"
+	}
+	c.Set(adminActorKey, models.ActorAdminPrefix+actor)
+	c.Next()
+}
+
+// HandleGrant grants currency to a user
+func (h *AdminHandler) HandleGrant(c *gin.Context) {
+	req, user, ok := h.bindCurrencyRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx := userContext(h.logger, c, user)
+	reward := models.Reward{Currency: req.Amount}
+	if err := h.economyService.Grant(ctx, user, c.GetString(adminActorKey), services.SourceAdmin, reward, req.Reason); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+
+	c.JSON(http.StatusOK, models.CurrencyResponse{Currency: h.economyService.Snapshot(user).Currency})
+}
+
+// HandleGrantItems grants items, such as pull tickets, to a user
+func (h *AdminHandler) HandleGrantItems(c *gin.Context) {
+	var req models.AdminItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := h.userService.GetUser(c.Request.Context(), req.Username)
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	ctx := userContext(h.logger, c, user)
+	reward := models.Reward{Items: map[string]int{req.ItemID: req.Quantity}}
+	err := h.economyService.Grant(ctx, user, c.GetString(adminActorKey), services.SourceAdmin, reward, req.Reason)
+	switch {
+	case errors.Is(err, services.ErrUnknownItem):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+
+	c.JSON(http.StatusOK, models.ItemsResponse{Items: h.economyService.Snapshot(user).Items})
+}
+
+// HandleRefund returns currency spent on pulls to a user, lifting it from
+// their monthly spending. Refunds of more than the user spent on pulls this
+// month are rejected, as they would raise the user's limit.
+func (h *AdminHandler) HandleRefund(c *gin.Context) {
+	req, user, ok := h.bindCurrencyRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx := userContext(h.logger, c, user)
+	err := h.economyService.Refund(ctx, user, c.GetString(adminActorKey), req.Amount, req.Reason)
+	switch {
+	case errors.Is(err, services.ErrRefundExceedsSpending):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Refund exceeds this month's pull spending"})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+
+	c.JSON(http.StatusOK, models.CurrencyResponse{Currency: h.economyService.Snapshot(user).Currency})
+}
+
+// HandleUpdateUser changes a user's age bracket
+func (h *AdminHandler) HandleUpdateUser(c *gin.Context) {
+	var req models.AdminUserUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.limitService.ValidBracket(req.AgeBracket) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid age bracket"})
+		return
+	}
+
+	user := h.userService.GetUser(c.Request.Context(), c.Param("username"))
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	old := user.AgeBracket
+	user.AgeBracket = req.AgeBracket
+	h.auditService.RecordChange(c.Request.Context(), c.GetString(adminActorKey), user, "ageBracket", old, req.AgeBracket)
+	requestLogger(h.logger, c, user).InfoContext(c.Request.Context(), "age bracket changed", "old", old, "new", req.AgeBracket)
+
+	c.JSON(http.StatusOK, h.limitService.Status(user))
+}
+
+// HandleQueryAudit returns audit entries filtered by user, actor, action and
+// time range (RFC 3339 from, inclusive, and to, exclusive)
+func (h *AdminHandler) HandleQueryAudit(c *gin.Context) {
+	filter := models.AuditFilter{
+		Username: c.Query("user"),
+		Actor:    c.Query("actor"),
+		Action:   c.Query("action"),
+	}
+
+	for name, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + " time, expected RFC 3339"})
+				return
+			}
+			*dst = t
+		}
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries := h.auditService.Query(filter)
+	c.JSON(http.StatusOK, models.AuditQueryResponse{
+		Entries: entries,
+		Count:   len(entries),
+	})
+}
+
+// bindCurrencyRequest parses a grant or refund and looks up its user
+func (h *AdminHandler) bindCurrencyRequest(c *gin.Context) (models.AdminCurrencyRequest, *models.User, bool) {
+	var req models.AdminCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, nil, false
+	}
+	if req.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must be positive"})
+		return req, nil, false
+	}
+
+	user := h.userService.GetUser(c.Request.Context(), req.Username)
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return req, nil, false
+	}
+	return req, user, true
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec subprotocol names, negotiated via Sec-WebSocket-Protocol
+const (
+	CodecJSON    = "gacha.json"
+	CodecMsgPack = "gacha.msgpack"
+)
+
+// Outbound is a message to be delivered to a client
+type Outbound struct {
+	Type  string
+	Seq   uint64 // Session sequence number, zero for control messages
+	Data  interface{}
+	Error string
+}
+
+// Codec encodes outbound and decodes inbound WebSocket messages
+type Codec interface {
+	// Name returns the subprotocol that selects this codec
+	Name() string
+	// FrameType returns the WebSocket frame type used for messages
+	FrameType() int
+	// Encode serializes an outbound message
+	Encode(msg Outbound) ([]byte, error)
+	// Decode parses a frame into its message type and raw payload
+	Decode(frame []byte) (msgType string, payload []byte, err error)
+	// DecodePayload unmarshals a raw payload into v
+	DecodePayload(payload []byte, v interface{}) error
+}
+
+var codecs = map[string]Codec{
+	CodecJSON:    jsonCodec{},
+	CodecMsgPack: msgpackCodec{},
+}
+
+// codecFor returns the codec for a negotiated subprotocol, defaulting to JSON
+func codecFor(subprotocol string) Codec {
+	if codec, ok := codecs[subprotocol]; ok {
+		return codec
+	}
+	return jsonCodec{}
+}
+
+// jsonCodec sends text frames with the payload JSON-encoded into the data string
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return CodecJSON }
+
+func (jsonCodec) FrameType() int { return websocket.TextMessage }
+
+func (jsonCodec) Encode(out Outbound) ([]byte, error) {
+	msg := WebSocketMessage{
+		Type:  out.Type,
+		Seq:   out.Seq,
+		Error: out.Error,
+	}
+
+	if out.Data != nil {
+		jsonData, err := json.Marshal(out.Data)
+		if err != nil {
+			return nil, err
+		}
+		msg.Data = string(jsonData)
+	}
+
+	return json.Marshal(msg)
+}
+
+func (jsonCodec) Decode(frame []byte) (string, []byte, error) {
+	var msg WebSocketMessage
+	if err := json.Unmarshal(frame, &msg); err != nil {
+		return "", nil, err
+	}
+	return msg.Type, []byte(msg.Data), nil
+}
+
+func (jsonCodec) DecodePayload(payload []byte, v interface{}) error {
+	return json.Unmarshal(payload, v)
+}
+
+// binaryMessage is the MessagePack envelope, with the payload embedded as a nested value
+type binaryMessage struct {
+	Type  string             `msgpack:"type"`
+	Seq   uint64             `msgpack:"seq,omitempty"`
+	Data  msgpack.RawMessage `msgpack:"data,omitempty"`
+	Error string             `msgpack:"error,omitempty"`
+}
+
+// msgpackCodec sends binary frames encoded with MessagePack, keying payload
+// fields by their JSON names so that both codecs share one schema
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string { return CodecMsgPack }
+
+func (msgpackCodec) FrameType() int { return websocket.BinaryMessage }
+
+func (msgpackCodec) Encode(out Outbound) ([]byte, error) {
+	msg := binaryMessage{
+		Type:  out.Type,
+		Seq:   out.Seq,
+		Error: out.Error,
+	}
+
+	if out.Data != nil {
+		packed, err := marshalPayload(out.Data)
+		if err != nil {
+			return nil, err
+		}
+		msg.Data = packed
+	}
+
+	return msgpack.Marshal(&msg)
+}
+
+func (msgpackCodec) Decode(frame []byte) (string, []byte, error) {
+	var msg binaryMessage
+	if err := msgpack.Unmarshal(frame, &msg); err != nil {
+		return "", nil, err
+	}
+	return msg.Type, msg.Data, nil
+}
+
+func (msgpackCodec) DecodePayload(payload []byte, v interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(payload))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+// marshalPayload encodes a payload with the keys of its JSON encoding, so
+// that both codecs share one schema
+func marshalPayload(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
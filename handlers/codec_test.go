@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"gacha/apidoc"
+	"gacha/models"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// fill sets every field reachable from v to a non-zero value, so that no key
+// is dropped by omitempty
+func fill(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			v.Set(reflect.ValueOf(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				fill(v.Field(i))
+			}
+		}
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem())
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0))
+	case reflect.Map:
+		key, elem := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+		fill(key)
+		fill(elem)
+		v.Set(reflect.MakeMap(v.Type()))
+		v.SetMapIndex(key, elem)
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		v.SetInt(3)
+	case reflect.Uint64, reflect.Uint32, reflect.Uint:
+		v.SetUint(3)
+	case reflect.Float64:
+		v.SetFloat(0.5)
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf("x"))
+	}
+}
+
+// TestCodecsRoundTripPayloadsWithJSONKeys checks that every documented payload
+// survives both codecs and that MessagePack keys it by its JSON field names
+func TestCodecsRoundTripPayloadsWithJSONKeys(t *testing.T) {
+	for _, msg := range apidoc.Messages {
+		if msg.Payload == nil {
+			continue
+		}
+		payloadType := reflect.TypeOf(msg.Payload)
+		sample := reflect.New(payloadType)
+		fill(sample.Elem())
+		want, err := json.Marshal(sample.Interface())
+		if err != nil {
+			t.Fatalf("%s: %v", msg.Type, err)
+		}
+
+		for _, codec := range []Codec{jsonCodec{}, msgpackCodec{}} {
+			frame, err := codec.Encode(Outbound{Type: msg.Type, Seq: 1, Data: sample.Interface()})
+			if err != nil {
+				t.Fatalf("%s over %s: encode: %v", msg.Type, codec.Name(), err)
+			}
+			msgType, payload, err := codec.Decode(frame)
+			if err != nil || msgType != msg.Type {
+				t.Fatalf("%s over %s: decoded %q, %v", msg.Type, codec.Name(), msgType, err)
+			}
+
+			decoded := reflect.New(payloadType)
+			if err := codec.DecodePayload(payload, decoded.Interface()); err != nil {
+				t.Fatalf("%s over %s: decode payload: %v", msg.Type, codec.Name(), err)
+			}
+			if got, _ := json.Marshal(decoded.Interface()); string(got) != string(want) {
+				t.Errorf("%s over %s round-tripped to %s, want %s", msg.Type, codec.Name(), got, want)
+			}
+
+			// Without the Go types, the MessagePack payload reads like the JSON one
+			if codec.Name() == CodecMsgPack {
+				var generic, wantGeneric interface{}
+				if err := msgpack.Unmarshal(payload, &generic); err != nil {
+					t.Fatalf("%s: %v", msg.Type, err)
+				}
+				json.Unmarshal(want, &wantGeneric)
+				got, _ := json.Marshal(generic)
+				normalized, _ := json.Marshal(wantGeneric)
+				if string(got) != string(normalized) {
+					t.Errorf("%s MessagePack payload reads %s, want the JSON keys of %s", msg.Type, got, normalized)
+				}
+			}
+		}
+	}
+}
+
+// veteranInventory builds an inventory the size of a long-time player's
+func veteranInventory(size int) models.InventoryResponse {
+	pool := models.GetCharacterPool()
+	inventory := make([]models.Character, size)
+	for i := range inventory {
+		inventory[i] = pool[i%len(pool)]
+		inventory[i].ID = i + 1
+	}
+	return models.InventoryResponse{
+		Inventory: inventory,
+		Count:     len(inventory),
+	}
+}
+
+func benchmarkEncode(b *testing.B, codec Codec) {
+	inventory := veteranInventory(2000)
+
+	var size int
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		frame, err := codec.Encode(Outbound{Type: TypeInventory, Data: inventory})
+		if err != nil {
+			b.Fatal(err)
+		}
+		size = len(frame)
+	}
+	b.ReportMetric(float64(size), "bytes/msg")
+}
+
+func benchmarkDecode(b *testing.B, codec Codec) {
+	frame, err := codec.Encode(Outbound{Type: TypeInventory, Data: veteranInventory(2000)})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, payload, err := codec.Decode(frame)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var inventory models.InventoryResponse
+		if err := codec.DecodePayload(payload, &inventory); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeJSON(b *testing.B)    { benchmarkEncode(b, jsonCodec{}) }
+func BenchmarkEncodeMsgPack(b *testing.B) { benchmarkEncode(b, msgpackCodec{}) }
+func BenchmarkDecodeJSON(b *testing.B)    { benchmarkDecode(b, jsonCodec{}) }
+func BenchmarkDecodeMsgPack(b *testing.B) { benchmarkDecode(b, msgpackCodec{}) }
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gacha/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrackDailyLogin grants the default user's daily login reward on their
+// first request of the day, pushing it to their WebSocket sessions
+func (h *UserHandler) TrackDailyLogin(c *gin.Context) {
+	if user := h.userService.GetDefaultUser(c.Request.Context()); user != nil {
+		ctx := userContext(h.logger, c, user)
+		reward, err := h.dailyService.Claim(ctx, user)
+		switch {
+		case err != nil:
+			requestLogger(h.logger, c, user).ErrorContext(ctx, "failed to claim daily reward", "error", err)
+		case reward != nil:
+			h.userService.Notify(ctx, services.UserUpdate{Username: user.Username, DailyReward: reward})
+		}
+	}
+	c.Next()
+}
+
+// HandleGetDaily returns the default user's login calendar and next reward
+func (h *UserHandler) HandleGetDaily(c *gin.Context) {
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	c.JSON(http.StatusOK, h.dailyService.Status(user))
+}
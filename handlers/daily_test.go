@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gacha/config"
+	"gacha/internal/testenv"
+)
+
+func TestWebSocketClaimsDailyRewardOncePerDay(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	h := NewWebSocketHandler(env.Gacha, env.Users, env.Economy, env.Daily, env.Missions, env.Seasons,
+		NewUserLimiters(env.Config.RateLimit.PerUser), env.Metrics, env.Logger, env.Config.RateLimit)
+	client := newTestClient("default")
+	user := env.Users.GetDefaultUser(context.Background())
+	ctx := context.Background()
+
+	h.claimDaily(ctx, client)
+	if user.Logins.LastClaim == "" {
+		t.Fatal("connection did not claim the daily reward")
+	}
+
+	// Until the reset, messages leave the daily service alone
+	user.Logins.LastClaim = ""
+	h.claimDaily(ctx, client)
+	if user.Logins.LastClaim != "" {
+		t.Fatal("second message claimed again before the reset")
+	}
+	if due := time.Unix(0, client.dailyDue.Load()); !due.Equal(env.Daily.NextReset()) {
+		t.Errorf("next claim due at %v, want %v", due, env.Daily.NextReset())
+	}
+
+	// The first message after the reset claims the new day's reward
+	client.dailyDue.Store(time.Now().UnixNano())
+	h.claimDaily(ctx, client)
+	if user.Logins.LastClaim == "" {
+		t.Error("message after the reset did not claim")
+	}
+}
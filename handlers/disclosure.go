@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gacha/models"
+	"gacha/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+var disclosureTemplate = template.Must(template.New("disclosure").Funcs(template.FuncMap{
+	"percent": formatPercent,
+	"stars": func(rarity int) string {
+		return strconv.Itoa(rarity) + "★"
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<title>Probability Disclosure - {{.BannerID}}</title>
+<style>
+	body { font-family: sans-serif; margin: 2em; color: #222; }
+	table { border-collapse: collapse; margin-bottom: 2em; }
+	th, td { border: 1px solid #999; padding: 4px 10px; text-align: right; }
+	th:first-child, td:first-child { text-align: left; }
+	.meta { color: #555; }
+	@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Probability Disclosure: {{.BannerID}}</h1>
+<p class="meta">Version {{.Version}}, generated {{.GeneratedAt}}</p>
+<h2>Rules</h2>
+<ul>{{range .Rules}}<li>{{.}}</li>{{end}}</ul>
+<h2>By Rarity</h2>
+<table>
+<tr><th>Rarity</th><th>Base rate</th><th>Consolidated (single pulls)</th><th>Consolidated (ten pulls)</th></tr>
+{{range .Rarities}}<tr><td>{{stars .Rarity}}</td><td>{{percent .BaseRate}}</td><td>{{percent .SinglePullRate}}</td><td>{{percent .TenPullRate}}</td></tr>
+{{end}}</table>
+<h2>By Character</h2>
+<table>
+<tr><th>Character</th><th>Rarity</th><th>Base rate</th><th>Consolidated (single pulls)</th><th>Consolidated (ten pulls)</th></tr>
+{{range .Characters}}<tr><td>{{.Name}}</td><td>{{stars .Rarity}}</td><td>{{percent .BaseRate}}</td><td>{{percent .SinglePullRate}}</td><td>{{percent .TenPullRate}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// HandleDisclosure returns the per-character probability disclosure of a
+// banner, as JSON or as a printable HTML page with format=html
+func (h *GachaHandler) HandleDisclosure(c *gin.Context) {
+	h.disclosure(c, respondV1)
+}
+
+// disclosure serves a banner's probability disclosure, rendering errors with fail
+func (h *GachaHandler) disclosure(c *gin.Context, fail errorResponder) {
+	banner, ok := models.FindBanner(c.DefaultQuery("banner", models.DefaultBanner().ID))
+	if !ok {
+		fail(c, &requestError{http.StatusNotFound, models.ErrorCodeNotFound, "Banner not found"})
+		return
+	}
+
+	engine := services.NewGachaServiceWithPool(banner.Characters, h.gachaService.PityThreshold(), nil)
+	disclosure := engine.Disclosure(banner.ID, time.Now())
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, disclosure)
+	case "html":
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		if err := disclosureTemplate.Execute(c.Writer, disclosure); err != nil {
+			c.Error(err)
+		}
+	default:
+		fail(c, &requestError{http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Unknown format"})
+	}
+}
+
+// formatPercent formats a probability as a percentage
+func formatPercent(p float64) string {
+	return strconv.FormatFloat(p*100, 'f', 4, 64) + "%"
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gacha/apidoc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIPage renders /openapi.json with Swagger UI loaded from a CDN
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<title>Gacha API</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>
+	window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+</script>
+</body>
+</html>
+`
+
+// DocsHandler serves the API documentation
+type DocsHandler struct {
+	openAPI        []byte
+	asyncAPI       []byte
+	messageSchemas map[string][]byte // JSON Schemas indexed by message type
+}
+
+// NewDocsHandler creates a new docs handler, rendering the documents once
+func NewDocsHandler() (*DocsHandler, error) {
+	openAPI, err := json.Marshal(apidoc.OpenAPI())
+	if err != nil {
+		return nil, err
+	}
+	asyncAPI, err := json.Marshal(apidoc.AsyncAPI())
+	if err != nil {
+		return nil, err
+	}
+
+	messageSchemas := make(map[string][]byte)
+	for _, msg := range apidoc.Messages {
+		schema, err := json.Marshal(msg.JSONSchema())
+		if err != nil {
+			return nil, err
+		}
+		messageSchemas[msg.Type] = schema
+	}
+
+	return &DocsHandler{
+		openAPI:        openAPI,
+		asyncAPI:       asyncAPI,
+		messageSchemas: messageSchemas,
+	}, nil
+}
+
+// HandleOpenAPI returns the OpenAPI document
+func (h *DocsHandler) HandleOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", h.openAPI)
+}
+
+// HandleSwaggerUI returns a Swagger UI page for the OpenAPI document
+func (h *DocsHandler) HandleSwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+// HandleAsyncAPI returns the AsyncAPI document of the WebSocket API
+func (h *DocsHandler) HandleAsyncAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", h.asyncAPI)
+}
+
+// HandleMessageSchema returns the JSON Schema of one WebSocket message type
+func (h *DocsHandler) HandleMessageSchema(c *gin.Context) {
+	schema, ok := h.messageSchemas[c.Param("type")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown message type"})
+		return
+	}
+	c.Data(http.StatusOK, "application/schema+json", schema)
+}
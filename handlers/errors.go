@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gacha/models"
+	"gacha/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report validation failures by JSON field name rather than Go field name
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// requestError is a rejected request, rendered by each API version in its
+// own format: {"error": message} in v1 and the error envelope in v2
+type requestError struct {
+	status  int
+	code    string
+	message string
+}
+
+// errorResponder renders a requestError for one API version
+type errorResponder func(c *gin.Context, err *requestError)
+
+// respondV1 renders a request error in the v1 format
+func respondV1(c *gin.Context, err *requestError) {
+	c.JSON(err.status, gin.H{"error": err.message})
+}
+
+// bindV1 decodes a v1 request body without running the binding tags, which
+// describe /api/v2 validation. v1 handlers check their fields by hand so that
+// their responses keep the messages v1 clients match on.
+func bindV1(c *gin.Context, req interface{}) error {
+	return json.NewDecoder(c.Request.Body).Decode(req)
+}
+
+// respondV2 renders a request error in the v2 error envelope
+func respondV2(c *gin.Context, err *requestError) {
+	respondError(c, err.status, err.code, err.message, nil)
+}
+
+// respondError writes the v2 error envelope
+func respondError(c *gin.Context, status int, code, message string, details interface{}) {
+	c.AbortWithStatusJSON(status, models.ErrorResponse{
+		Error: models.APIError{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	})
+}
+
+// respondBindError writes a v2 invalid_request error for a request body that
+// failed to decode or validate, listing the offending fields
+func respondBindError(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]models.FieldError, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			fields[i] = models.FieldError{
+				Field:  fieldErr.Field(),
+				Rule:   fieldErr.Tag(),
+				Param:  fieldErr.Param(),
+				Reason: fieldReason(fieldErr),
+			}
+		}
+		respondError(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Request validation failed", fields)
+		return
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		respondError(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Request validation failed", []models.FieldError{{
+			Field:  typeErr.Field,
+			Rule:   "type",
+			Param:  typeErr.Type.String(),
+			Reason: "must be of type " + typeErr.Type.String(),
+		}})
+		return
+	}
+
+	respondError(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Malformed request body", nil)
+}
+
+// fieldReason describes a validation failure in words
+func fieldReason(err validator.FieldError) string {
+	switch err.Tag() {
+	case "required":
+		return "is required"
+	case "gt":
+		return "must be greater than " + err.Param()
+	case "gte":
+		return "must be at least " + err.Param()
+	case "max":
+		return "must be at most " + err.Param() + " long"
+	case "oneof":
+		return "must be one of: " + err.Param()
+	}
+	return fmt.Sprintf("failed the %q rule", err.Tag())
+}
+
+// respondEconomyError writes the v2 error for a failed pull or purchase
+func respondEconomyError(c *gin.Context, err error) {
+	var limitErr *services.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		respondError(c, http.StatusForbidden, models.ErrorCodeSpendingLimit, err.Error(), limitDetails(limitErr))
+	case errors.Is(err, services.ErrInsufficientCurrency):
+		respondError(c, http.StatusPaymentRequired, models.ErrorCodeInsufficientCurrency, "Insufficient currency", nil)
+	case errors.Is(err, services.ErrInsufficientTickets):
+		respondError(c, http.StatusPaymentRequired, models.ErrorCodeInsufficientTickets, "Insufficient pull tickets", nil)
+	case errors.Is(err, services.ErrInvalidPull):
+		respondError(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, err.Error(), nil)
+	case errors.Is(err, services.ErrInvalidAmount):
+		respondError(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Amount must be positive", nil)
+	case errors.Is(err, services.ErrUnknownOffer), errors.Is(err, services.ErrUnknownItem), errors.Is(err, services.ErrUnknownMission),
+		errors.Is(err, services.ErrUnknownTier), errors.Is(err, services.ErrUnknownTrack):
+		respondError(c, http.StatusNotFound, models.ErrorCodeNotFound, err.Error(), nil)
+	case errors.Is(err, services.ErrMissionIncomplete):
+		respondError(c, http.StatusConflict, models.ErrorCodeMissionIncomplete, "Mission not completed", nil)
+	case errors.Is(err, services.ErrMissionClaimed):
+		respondError(c, http.StatusConflict, models.ErrorCodeMissionClaimed, "Mission reward already claimed", nil)
+	case errors.Is(err, services.ErrNoActiveSeason):
+		respondError(c, http.StatusConflict, models.ErrorCodeSeasonInactive, "No active season", nil)
+	case errors.Is(err, services.ErrTierLocked):
+		respondError(c, http.StatusConflict, models.ErrorCodeTierLocked, "Season tier not reached", nil)
+	case errors.Is(err, services.ErrTierClaimed):
+		respondError(c, http.StatusConflict, models.ErrorCodeTierClaimed, "Season tier reward already claimed", nil)
+	case errors.Is(err, services.ErrPremiumRequired):
+		respondError(c, http.StatusConflict, models.ErrorCodePremiumRequired, "Premium season pass required", nil)
+	case errors.Is(err, services.ErrPremiumOwned):
+		respondError(c, http.StatusConflict, models.ErrorCodePremiumOwned, "Premium season pass already owned", nil)
+	default:
+		respondError(c, http.StatusInternalServerError, models.ErrorCodeInternal, "Internal error", nil)
+		c.Error(err)
+	}
+}
+
+// respondRateLimited writes a v2 rate_limited error with a Retry-After header
+func respondRateLimited(c *gin.Context, msgType string, retryAfter time.Duration) {
+	c.Header("Retry-After", strconv.FormatInt(int64((retryAfter+time.Second-1)/time.Second), 10))
+	respondError(c, http.StatusTooManyRequests, models.ErrorCodeRateLimited, "Rate limit exceeded", models.RateLimitResponse{
+		MessageType:  msgType,
+		RetryAfterMs: retryAfterMs(retryAfter),
+	})
+}
+
+// retryAfterMs rounds a retry delay up to whole milliseconds
+func retryAfterMs(retryAfter time.Duration) int64 {
+	return (retryAfter + time.Millisecond - 1).Milliseconds()
+}
+
+// limitDetails describes a spending limit rejection
+func limitDetails(err *services.LimitError) models.LimitDetails {
+	return models.LimitDetails{
+		Limit:     err.Limit,
+		Used:      err.Used,
+		Requested: err.Requested,
+		ResetsAt:  err.ResetsAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"gacha/fairness"
+	"gacha/models"
+	"gacha/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FairnessHandler handles provably fair seed requests
+type FairnessHandler struct {
+	fairnessService *services.FairnessService
+	userService     *services.UserService
+	logger          *slog.Logger
+}
+
+// NewFairnessHandler creates a new fairness handler
+func NewFairnessHandler(fairnessService *services.FairnessService, userService *services.UserService, logger *slog.Logger) *FairnessHandler {
+	return &FairnessHandler{
+		fairnessService: fairnessService,
+		userService:     userService,
+		logger:          logger,
+	}
+}
+
+// HandleGetCommitment returns the hash of the active server seed
+func (h *FairnessHandler) HandleGetCommitment(c *gin.Context) {
+	if !h.fairnessService.Enabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provably fair mode is disabled"})
+		return
+	}
+
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	c.JSON(http.StatusOK, h.fairnessService.Commitment(user.Username))
+}
+
+// HandleSetClientSeed changes the client seed used for future pulls
+func (h *FairnessHandler) HandleSetClientSeed(c *gin.Context) {
+	if !h.fairnessService.Enabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provably fair mode is disabled"})
+		return
+	}
+
+	var req models.ClientSeedRequest
+	if err := bindV1(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ClientSeed == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Client seed must not be empty"})
+		return
+	}
+
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	c.JSON(http.StatusOK, h.fairnessService.SetClientSeed(user.Username, req.ClientSeed))
+}
+
+// HandleRotate reveals the active server seed and commits to a new one
+func (h *FairnessHandler) HandleRotate(c *gin.Context) {
+	if !h.fairnessService.Enabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provably fair mode is disabled"})
+		return
+	}
+
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	revealed := h.fairnessService.Rotate(user.Username)
+	requestLogger(h.logger, c, user).InfoContext(c.Request.Context(), "server seed rotated", "nonces_used", revealed.NoncesUsed)
+	c.JSON(http.StatusOK, revealed)
+}
+
+// HandleVerify recomputes a pull from a revealed server seed
+func (h *FairnessHandler) HandleVerify(c *gin.Context) {
+	var req models.VerifyRequest
+	if err := bindV1(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := fairness.Verify(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -1,9 +1,16 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"net/http"
-	"time"
+	"strconv"
 
+	"gacha/config"
 	"gacha/models"
 	"gacha/services"
 
@@ -12,76 +19,172 @@ import (
 
 // GachaHandler handles gacha-related requests
 type GachaHandler struct {
-	gachaService *services.GachaService
-	userService  *services.UserService
+	gachaService   *services.GachaService
+	userService    *services.UserService
+	economyService *services.EconomyService
+	userLimiters   *UserLimiters
+	logger         *slog.Logger
+	gachaConfig    config.GachaConfig
 }
 
 // NewGachaHandler creates a new gacha handler
-func NewGachaHandler(gachaService *services.GachaService, userService *services.UserService) *GachaHandler {
+func NewGachaHandler(gachaService *services.GachaService, userService *services.UserService, economyService *services.EconomyService, userLimiters *UserLimiters, logger *slog.Logger, gachaConfig config.GachaConfig) *GachaHandler {
 	return &GachaHandler{
-		gachaService: gachaService,
-		userService:  userService,
+		gachaService:   gachaService,
+		userService:    userService,
+		economyService: economyService,
+		userLimiters:   userLimiters,
+		logger:         logger,
+		gachaConfig:    gachaConfig,
 	}
 }
 
-// HandleSinglePull handles single pull request
-func (h *GachaHandler) HandleSinglePull(c *gin.Context) {
-	user := h.userService.GetDefaultUser()
+// HandlePull handles a pull request: a single pull without a body, or count
+// pulls that may stop early on a condition
+func (h *GachaHandler) HandlePull(c *gin.Context) {
+	req, ok := bindPullRequest(c, func(err error) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	})
+	if !ok {
+		return
+	}
+
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+	result, err := h.economyService.PullMany(ctx, user, req)
+	h.respondPull(c, ctx, user, result, err)
+}
+
+// HandleTenPull handles ten pull request
+func (h *GachaHandler) HandleTenPull(c *gin.Context) {
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+	result, err := h.economyService.Pull(ctx, user, models.PullTypeTen)
+	h.respondPull(c, ctx, user, result, err)
+}
 
-	if !user.DeductCurrency(160) {
+// respondPull writes a pull result, or its failure in the v1 error bodies
+func (h *GachaHandler) respondPull(c *gin.Context, ctx context.Context, user *models.User, result models.GachaResult, err error) {
+	var limitErr *services.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		respondLimitError(c, err)
+		return
+	case errors.Is(err, services.ErrInsufficientCurrency):
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient currency"})
 		return
+	case errors.Is(err, services.ErrInsufficientTickets):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient pull tickets"})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	char := h.gachaService.PerformSinglePull(user)
-	isNew := user.AddCharacter(char)
+	h.userService.NotifyUpdate(ctx, user.Username, newCharacters(result))
+	c.JSON(http.StatusOK, result)
+}
 
-	result := models.GachaResult{
-		Characters: []models.Character{char},
-		IsNew:      []bool{isNew},
-		Timestamp:  time.Now().Unix(),
+// bindPullRequest parses an optional pull request body, an empty body being
+// a single pull, and reports invalid bodies through fail
+func bindPullRequest(c *gin.Context, fail func(error)) (models.PullRequest, bool) {
+	var req models.PullRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		fail(err)
+		return req, false
 	}
+	return req, true
+}
 
-	c.JSON(http.StatusOK, result)
+// HandleGetPool returns the gacha pool information
+func (h *GachaHandler) HandleGetPool(c *gin.Context) {
+	c.JSON(http.StatusOK, poolInfo(h.gachaService))
 }
 
-// HandleTenPull handles ten pull request
-func (h *GachaHandler) HandleTenPull(c *gin.Context) {
-	user := h.userService.GetDefaultUser()
+// HandleCalculator returns the odds and expected cost of obtaining a target character
+func (h *GachaHandler) HandleCalculator(c *gin.Context) {
+	h.calculator(c, respondV1)
+}
 
-	if !user.DeductCurrency(1600) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient currency"})
+// calculator serves the odds calculator, rendering errors with fail
+func (h *GachaHandler) calculator(c *gin.Context, fail errorResponder) {
+	banner, ok := models.FindBanner(c.DefaultQuery("banner", models.DefaultBanner().ID))
+	if !ok {
+		fail(c, &requestError{http.StatusNotFound, models.ErrorCodeNotFound, "Banner not found"})
+		return
+	}
+
+	targetQuery := c.Query("target")
+	if targetQuery == "" && len(banner.FeaturedIDs) > 0 {
+		targetQuery = strconv.Itoa(banner.FeaturedIDs[0])
+	}
+	target, ok := banner.FindCharacter(targetQuery)
+	if !ok {
+		fail(c, &requestError{http.StatusNotFound, models.ErrorCodeNotFound, "Target character not found"})
 		return
 	}
 
-	characters := h.gachaService.PerformTenPull(user)
-	var isNewList []bool
+	// Default to the current user's pity
+	pity := h.userService.GetDefaultUser(c.Request.Context()).PityCount
+	if pityQuery := c.Query("pity"); pityQuery != "" {
+		value, err := strconv.Atoi(pityQuery)
+		if err != nil || value < 0 || value >= h.gachaService.PityThreshold() {
+			fail(c, &requestError{http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Invalid pity"})
+			return
+		}
+		pity = value
+	}
 
-	for _, char := range characters {
-		isNew := user.AddCharacter(char)
-		isNewList = append(isNewList, isNew)
+	guaranteed, err := strconv.ParseBool(c.DefaultQuery("guaranteed", "false"))
+	if err != nil {
+		fail(c, &requestError{http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Invalid guaranteed flag"})
+		return
 	}
 
-	result := models.GachaResult{
-		Characters: characters,
-		IsNew:      isNewList,
-		Timestamp:  time.Now().Unix(),
+	pulls, err := strconv.Atoi(c.DefaultQuery("pulls", strconv.Itoa(h.gachaService.PityThreshold())))
+	if err != nil || pulls < 1 || pulls > services.MaxCalculatorPulls {
+		fail(c, &requestError{http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Invalid pulls"})
+		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	engine := services.NewGachaServiceWithPool(banner.Characters, h.gachaService.PityThreshold(), nil)
+	response, err := engine.CalculateOdds(target, pity, guaranteed, pulls, h.gachaConfig.SinglePullCost)
+	if err != nil {
+		fail(c, &requestError{http.StatusBadRequest, models.ErrorCodeInvalidRequest, err.Error()})
+		return
+	}
+	response.BannerID = banner.ID
+
+	c.JSON(http.StatusOK, response)
 }
 
-// HandleGetPool returns the gacha pool information
-func (h *GachaHandler) HandleGetPool(c *gin.Context) {
-	poolInfo := models.PoolInfo{
-		Characters: h.gachaService.GetCharacterPool(),
+// poolInfo describes the pool with rates computed from its live weights
+func poolInfo(gachaService *services.GachaService) models.PoolInfo {
+	rates := gachaService.RarityRates()
+
+	return models.PoolInfo{
+		Characters: gachaService.GetCharacterPool(),
 		Rates: map[string]string{
-			"ssr": "2%",
-			"sr":  "10%",
-			"r":   "88%",
+			"ssr": formatRate(rates[5]),
+			"sr":  formatRate(rates[4]),
+			"r":   formatRate(rates[3]),
 		},
-		PitySystem: "Guaranteed SSR at 90 pulls",
+		PitySystem: fmt.Sprintf("Guaranteed SSR at %d pulls", gachaService.PityThreshold()),
 	}
+}
+
+// formatRate formats a rarity rate as a whole-basis-point percentage, e.g. "2%"
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(math.Round(rate*10000)/100, 'f', -1, 64) + "%"
+}
 
-	c.JSON(http.StatusOK, poolInfo)
+// newCharacters returns the characters in a result that were newly acquired
+func newCharacters(result models.GachaResult) []models.Character {
+	var added []models.Character
+	for i, char := range result.Characters {
+		if result.IsNew[i] {
+			added = append(added, char)
+		}
+	}
+	return added
 }
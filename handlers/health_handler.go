@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"gacha/config"
+	"gacha/models"
+	"gacha/services"
+	"gacha/version"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessCheckTimeout bounds how long a single readiness check may take
+const readinessCheckTimeout = 2 * time.Second
+
+// HealthHandler serves liveness, readiness and build information for orchestrators
+type HealthHandler struct {
+	cfg          *config.Config
+	userService  *services.UserService
+	auditService *services.AuditService
+	draining     atomic.Bool
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(cfg *config.Config, userService *services.UserService, auditService *services.AuditService) *HealthHandler {
+	return &HealthHandler{
+		cfg:          cfg,
+		userService:  userService,
+		auditService: auditService,
+	}
+}
+
+// BeginDrain makes readiness report not-ready so load balancers stop routing
+// new traffic while the server shuts down
+func (h *HealthHandler) BeginDrain() {
+	h.draining.Store(true)
+}
+
+// HandleHealthz reports that the process is alive
+func (h *HealthHandler) HandleHealthz(c *gin.Context) {
+	c.JSON(http.StatusOK, models.HealthResponse{Status: models.StatusOK})
+}
+
+// HandleReadyz reports whether storage is reachable, the audit log is
+// writable, the config is valid and every banner can be pulled from, and
+// not-ready while draining
+func (h *HealthHandler) HandleReadyz(c *gin.Context) {
+	checks := map[string]func() error{
+		"storage": h.userService.Ping,
+		"audit":   h.auditService.Healthy,
+		"config":  h.cfg.Validate,
+		"banners": validateBanners,
+	}
+
+	response := models.ReadinessResponse{
+		Status: models.StatusOK,
+		Checks: make(map[string]string, len(checks)),
+	}
+	for name, check := range checks {
+		if err := runCheck(check); err != nil {
+			response.Status = models.StatusNotReady
+			response.Checks[name] = err.Error()
+		} else {
+			response.Checks[name] = models.StatusOK
+		}
+	}
+	if h.draining.Load() {
+		response.Status = models.StatusDraining
+	}
+
+	status := http.StatusOK
+	if response.Status != models.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, response)
+}
+
+// HandleVersion returns the build's git commit and time and the config hash
+func (h *HealthHandler) HandleVersion(c *gin.Context) {
+	info := version.Get()
+
+	c.JSON(http.StatusOK, models.VersionResponse{
+		Commit:     info.Commit,
+		BuildTime:  info.BuildTime,
+		Modified:   info.Modified,
+		GoVersion:  info.GoVersion,
+		ConfigHash: h.cfg.Hash(),
+	})
+}
+
+// validateBanners checks every available banner
+func validateBanners() error {
+	for _, banner := range models.GetBanners() {
+		if err := banner.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runCheck runs a readiness check, failing it if it does not finish in time
+func runCheck(check func() error) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- check()
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(readinessCheckTimeout):
+		return errors.New("check timed out")
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gacha/config"
+	"gacha/logging"
+	"gacha/models"
+	"gacha/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newHealthHandler(t *testing.T, cfg *config.Config) *HealthHandler {
+	t.Helper()
+	audit, err := services.NewAuditService("", logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewHealthHandler(cfg, services.NewUserService(logging.Discard()), audit)
+}
+
+func readyz(t *testing.T, h *HealthHandler) (int, models.ReadinessResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+	h.HandleReadyz(c)
+
+	var response models.ReadinessResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	return recorder.Code, response
+}
+
+func TestReadinessFlipsDuringDrain(t *testing.T) {
+	h := newHealthHandler(t, config.LoadConfig())
+
+	code, response := readyz(t, h)
+	if code != http.StatusOK || response.Status != models.StatusOK {
+		t.Fatalf("ready before drain: %d %+v", code, response)
+	}
+
+	h.BeginDrain()
+
+	code, response = readyz(t, h)
+	if code != http.StatusServiceUnavailable || response.Status != models.StatusDraining {
+		t.Errorf("ready while draining: %d %+v", code, response)
+	}
+}
+
+func TestReadinessReportsInvalidConfig(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.Gacha.SSRRate = 0.5
+
+	code, response := readyz(t, newHealthHandler(t, cfg))
+	if code != http.StatusServiceUnavailable || response.Checks["config"] == models.StatusOK {
+		t.Errorf("ready with invalid rates: %d %+v", code, response)
+	}
+}
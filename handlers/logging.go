@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"gacha/logging"
+	"gacha/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestLogger returns logger annotated with the request ID and acting user
+func requestLogger(logger *slog.Logger, c *gin.Context, user *models.User) *slog.Logger {
+	return logging.FromContext(c.Request.Context(), logger).With(logging.UserIDKey, user.ID)
+}
+
+// userContext returns the request context carrying a logger annotated with
+// the acting user, for the services a handler calls
+func userContext(logger *slog.Logger, c *gin.Context, user *models.User) context.Context {
+	return logging.NewContext(c.Request.Context(), requestLogger(logger, c, user))
+}
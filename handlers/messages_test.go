@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gacha/apidoc"
+	"gacha/config"
+	"gacha/internal/testenv"
+	"gacha/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// newMessageServer serves /ws and the message schemas, with a per-connection
+// get_pool limit low enough to provoke rate_limited
+func newMessageServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := config.LoadConfig()
+	cfg.Gacha.ProvablyFair = true // Pull results carry their proofs
+	cfg.RateLimit.PerConnection[TypeGetPool] = config.RateLimit{Rate: 0.001, Burst: 1}
+	// A season running now, whose first tier the daily login alone reaches
+	season := config.QuarterlySeason(time.Now())
+	season.Start, season.End = time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	season.XPPerTier = cfg.Season.XP.Login
+	cfg.Season.Seasons = []models.Season{season}
+	env := testenv.New(t, cfg)
+	wsHandler := NewWebSocketHandler(env.Gacha, env.Users, env.Economy, env.Daily, env.Missions, env.Seasons, NewUserLimiters(cfg.RateLimit.PerUser), env.Metrics, env.Logger, cfg.RateLimit)
+	docsHandler, err := NewDocsHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.GET("/ws", wsHandler.HandleWebSocket)
+	r.GET("/schemas/ws/:type", docsHandler.HandleMessageSchema)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// compileSchemas fetches and compiles the served schema of every documented message type
+func compileSchemas(t *testing.T, server *httptest.Server) map[string]*jsonschema.Schema {
+	t.Helper()
+	compiler := jsonschema.NewCompiler()
+	schemas := make(map[string]*jsonschema.Schema)
+
+	for _, msg := range apidoc.Messages {
+		resp, err := http.Get(server.URL + "/schemas/ws/" + msg.Type)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("schema of %s: status %d", msg.Type, resp.StatusCode)
+		}
+
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("schema of %s: %v", msg.Type, err)
+		}
+		url := "mem:///" + msg.Type + ".json"
+		if err := compiler.AddResource(url, doc); err != nil {
+			t.Fatal(err)
+		}
+		if schemas[msg.Type], err = compiler.Compile(url); err != nil {
+			t.Fatalf("compile schema of %s: %v", msg.Type, err)
+		}
+	}
+	return schemas
+}
+
+// decodeEnvelope converts a frame to the decoded envelope the schemas
+// describe, with data as the payload value rather than its encoding
+func decodeEnvelope(codec Codec, frame []byte) (map[string]interface{}, error) {
+	var envelope map[string]interface{}
+	if codec.Name() == CodecMsgPack {
+		if err := msgpack.Unmarshal(frame, &envelope); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := json.Unmarshal(frame, &envelope); err != nil {
+			return nil, err
+		}
+		if data, ok := envelope["data"].(string); ok {
+			var payload interface{}
+			if err := json.Unmarshal([]byte(data), &payload); err != nil {
+				return nil, err
+			}
+			envelope["data"] = payload
+		}
+	}
+
+	// Round trip through JSON so numbers and maps take the validator's types
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+	var decoded map[string]interface{}
+	return decoded, json.Unmarshal(data, &decoded)
+}
+
+// validate checks a decoded envelope against the schema of its type
+func validate(schemas map[string]*jsonschema.Schema, envelope map[string]interface{}) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	msgType, _ := envelope["type"].(string)
+	schema, ok := schemas[msgType]
+	if !ok {
+		return &undocumentedError{msgType}
+	}
+	return schema.Validate(instance)
+}
+
+type undocumentedError struct {
+	msgType string
+}
+
+func (e *undocumentedError) Error() string {
+	return "undocumented message type " + e.msgType
+}
+
+func TestMessagesMatchSchemas(t *testing.T) {
+	schemas := compileSchemas(t, newMessageServer(t))
+
+	// One of each inbound type, plus what it takes to provoke every outbound type
+	inbound := []Outbound{
+		{Type: TypeSinglePull},
+		{Type: TypeTenPull},
+		{Type: TypePull, Data: models.PullRequest{Count: 5, StopOn: &models.StopCondition{Rarity: 5}}},
+		{Type: TypeGetUserInfo},
+		{Type: TypeGetInventory},
+		{Type: TypeGetPool},
+		{Type: TypeGetPool}, // rate_limited
+		{Type: TypeAddCurrency, Data: models.AddCurrencyRequest{Amount: 100}},
+		{Type: TypeGetMissions},
+		{Type: TypeClaimMission, Data: models.MissionClaimRequest{MissionID: "daily_pull_1"}}, // Completed by the pulls above
+		{Type: TypeGetSeason},
+		{Type: TypeClaimTier, Data: models.SeasonClaimRequest{Tier: 1, Track: models.TrackFree}},
+		{Type: TypeResume, Data: models.ResumeRequest{SessionID: "unknown", LastSeq: 3}},
+		{Type: TypePing},
+	}
+
+	for _, codec := range []Codec{jsonCodec{}, msgpackCodec{}} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			// A fresh server per codec, so that each connection claims the daily reward
+			server := newMessageServer(t)
+			dialer := websocket.Dialer{Subprotocols: []string{codec.Name()}}
+			conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			for _, msg := range inbound {
+				frame, err := codec.Encode(msg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				envelope, err := decodeEnvelope(codec, frame)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := validate(schemas, envelope); err != nil {
+					t.Errorf("inbound %s: %v", msg.Type, err)
+				}
+				if err := conn.WriteMessage(codec.FrameType(), frame); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// An unknown type provokes error
+			frame, _ := codec.Encode(Outbound{Type: "no_such_type"})
+			conn.WriteMessage(codec.FrameType(), frame)
+
+			seen := make(map[string]bool)
+			unknown := 0
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			for len(seen) < outboundCount() || unknown == 0 {
+				_, frame, err := conn.ReadMessage()
+				if err != nil {
+					break
+				}
+				envelope, err := decodeEnvelope(codec, frame)
+				if err != nil {
+					t.Fatal(err)
+				}
+				msgType, _ := envelope["type"].(string)
+				if err := validate(schemas, envelope); err != nil {
+					t.Errorf("outbound %s: %v", msgType, err)
+				}
+				if msgType == TypeError && envelope["error"] == "Unknown message type" {
+					unknown++
+				}
+				seen[msgType] = true
+			}
+
+			// Only no_such_type is unknown to the handler
+			if unknown != 1 {
+				t.Errorf("%d messages rejected as unknown, want 1", unknown)
+			}
+
+			for _, msg := range apidoc.Messages {
+				if !msg.Inbound && !seen[msg.Type] {
+					t.Errorf("never received documented type %s", msg.Type)
+				}
+			}
+		})
+	}
+}
+
+func outboundCount() int {
+	n := 0
+	for _, msg := range apidoc.Messages {
+		if !msg.Inbound {
+			n++
+		}
+	}
+	return n
+}
+
+func TestAddCurrencyRejectsNonPositiveAmounts(t *testing.T) {
+	server := newMessageServer(t)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	codec := jsonCodec{}
+	for _, msg := range []Outbound{
+		{Type: TypeAddCurrency, Data: models.AddCurrencyRequest{Amount: -5}},
+		{Type: TypeAddCurrency, Data: models.AddCurrencyRequest{Amount: 0}},
+		{Type: TypePing},
+	} {
+		frame, err := codec.Encode(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.WriteMessage(codec.FrameType(), frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Both amounts are rejected, and the connection still answers the ping
+	rejected := 0
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, frame, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("after %d rejections: %v", rejected, err)
+		}
+		var msg WebSocketMessage
+		if err := json.Unmarshal(frame, &msg); err != nil {
+			t.Fatal(err)
+		}
+		switch msg.Type {
+		case TypeCurrencyUpdate:
+			t.Fatalf("non-positive amount purchased: %s", msg.Data)
+		case TypeError:
+			if msg.Error == "Amount must be positive" {
+				rejected++
+			}
+		case TypePong:
+			if rejected != 2 {
+				t.Errorf("%d amounts rejected, want 2", rejected)
+			}
+			return
+		}
+	}
+}
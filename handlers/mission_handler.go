@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"gacha/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MissionHandler handles mission and achievement requests
+type MissionHandler struct {
+	userService    *services.UserService
+	missionService *services.MissionService
+	logger         *slog.Logger
+}
+
+// NewMissionHandler creates a new mission handler
+func NewMissionHandler(userService *services.UserService, missionService *services.MissionService, logger *slog.Logger) *MissionHandler {
+	return &MissionHandler{
+		userService:    userService,
+		missionService: missionService,
+		logger:         logger,
+	}
+}
+
+// HandleListMissions returns the default user's progress on every mission
+func (h *MissionHandler) HandleListMissions(c *gin.Context) {
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	c.JSON(http.StatusOK, h.missionService.List(user))
+}
+
+// HandleClaimMission grants the reward of a completed mission
+func (h *MissionHandler) HandleClaimMission(c *gin.Context) {
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+
+	status, err := h.missionService.Claim(ctx, user, c.Param("id"))
+	switch {
+	case errors.Is(err, services.ErrUnknownMission):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mission not found"})
+		return
+	case errors.Is(err, services.ErrMissionIncomplete), errors.Is(err, services.ErrMissionClaimed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+	c.JSON(http.StatusOK, status)
+}
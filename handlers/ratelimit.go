@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"gacha/config"
+	"gacha/models"
+)
+
+const (
+	defaultLimitKey    = "*"       // Selects the limit for message types without their own entry
+	invalidMessageType = "invalid" // Limits frames that fail to decode
+)
+
+// tokenBucket is a token bucket rate limiter
+type tokenBucket struct {
+	limit  config.RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accrued since the bucket was last used
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+	b.last = now
+}
+
+// wait returns how long until a token is available
+func (b *tokenBucket) wait() time.Duration {
+	return time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+}
+
+// rateLimiter applies per message type token buckets to one connection or user
+type rateLimiter struct {
+	limits   map[string]config.RateLimit
+	buckets  map[string]*tokenBucket
+	lastUsed time.Time
+	mu       sync.Mutex
+}
+
+// newRateLimiter creates a rate limiter with limits keyed by message type
+func newRateLimiter(limits map[string]config.RateLimit) *rateLimiter {
+	return &rateLimiter{
+		limits:  limits,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// bucket returns the refilled bucket limiting a message type, or nil when
+// the type is unlimited, called with mu held
+func (l *rateLimiter) bucket(msgType string, now time.Time) *tokenBucket {
+	l.lastUsed = now
+
+	key := msgType
+	limit, ok := l.limits[key]
+	if !ok {
+		key = defaultLimitKey
+		limit, ok = l.limits[key]
+	}
+	if !ok || limit.Rate <= 0 {
+		return nil
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{
+			limit:  limit,
+			tokens: float64(limit.Burst),
+			last:   now,
+		}
+		l.buckets[key] = bucket
+	}
+	bucket.refill(now)
+	return bucket
+}
+
+// idleSince reports whether the limiter has been unused since cutoff
+func (l *rateLimiter) idleSince(cutoff time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lastUsed.Before(cutoff)
+}
+
+// allowAll reports whether a message of the given type may be processed now
+// under every limiter, and otherwise how long until it may be retried. A token
+// is taken from each limiter only when all of them have one.
+func allowAll(msgType string, now time.Time, limiters ...*rateLimiter) (bool, time.Duration) {
+	buckets := make([]*tokenBucket, 0, len(limiters))
+	for _, limiter := range limiters {
+		limiter.mu.Lock()
+		defer limiter.mu.Unlock()
+		if bucket := limiter.bucket(msgType, now); bucket != nil {
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	var wait time.Duration
+	for _, bucket := range buckets {
+		if bucket.tokens < 1 {
+			wait = max(wait, bucket.wait())
+		}
+	}
+	if wait > 0 {
+		return false, wait
+	}
+
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+	return true, 0
+}
+
+// UserLimiters holds the rate limiters shared by all WebSocket connections
+// and HTTP requests of each user. Limiters unused for long enough to have
+// refilled are evicted.
+type UserLimiters struct {
+	limits    map[string]config.RateLimit
+	limiters  map[string]*rateLimiter
+	idleAfter time.Duration // Time for every bucket to refill from empty
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+// NewUserLimiters creates per-user rate limiters with limits keyed by message type
+func NewUserLimiters(limits map[string]config.RateLimit) *UserLimiters {
+	idleAfter := time.Minute
+	for _, limit := range limits {
+		if limit.Rate > 0 {
+			idleAfter = max(idleAfter, time.Duration(float64(limit.Burst)/limit.Rate*float64(time.Second)))
+		}
+	}
+
+	return &UserLimiters{
+		limits:    limits,
+		limiters:  make(map[string]*rateLimiter),
+		idleAfter: idleAfter,
+	}
+}
+
+// limiter returns a user's limiter, evicting idle limiters at most once per idleAfter
+func (u *UserLimiters) limiter(username string, now time.Time) *rateLimiter {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if now.Sub(u.lastSweep) >= u.idleAfter {
+		cutoff := now.Add(-u.idleAfter)
+		for name, limiter := range u.limiters {
+			if limiter.idleSince(cutoff) {
+				delete(u.limiters, name)
+			}
+		}
+		u.lastSweep = now
+	}
+
+	limiter, ok := u.limiters[username]
+	if !ok {
+		limiter = newRateLimiter(u.limits)
+		u.limiters[username] = limiter
+	}
+	return limiter
+}
+
+// allow applies the user's limit for a message type
+func (u *UserLimiters) allow(username, msgType string, now time.Time) (bool, time.Duration) {
+	return allowAll(msgType, now, u.limiter(username, now))
+}
+
+// allowMessage applies the connection and user rate limits to an inbound
+// message, replying with rate_limited when either is exhausted
+func (h *WebSocketHandler) allowMessage(client *Client, msgType string) bool {
+	now := time.Now()
+
+	ok, retryAfter := allowAll(msgType, now, client.limiter, h.userLimiters.limiter(client.username, now))
+	if ok {
+		return true
+	}
+
+	client.log.Info("rate limited", "type", msgType, "retry_after", retryAfter)
+	client.enqueue(Outbound{
+		Type:  TypeRateLimited,
+		Error: "Rate limit exceeded",
+		Data: models.RateLimitResponse{
+			MessageType:  msgType,
+			RetryAfterMs: retryAfterMs(retryAfter),
+		},
+	})
+	return false
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"gacha/config"
+)
+
+func TestAllowAllTakesNoTokenWhenAnyLimiterRejects(t *testing.T) {
+	now := time.Now()
+	conn := newRateLimiter(map[string]config.RateLimit{"*": {Rate: 0.001, Burst: 2}})
+	user := newRateLimiter(map[string]config.RateLimit{"*": {Rate: 0.001, Burst: 1}})
+
+	if ok, _ := allowAll(TypeGetPool, now, conn, user); !ok {
+		t.Fatal("first message rejected")
+	}
+	if ok, retryAfter := allowAll(TypeGetPool, now, conn, user); ok || retryAfter <= 0 {
+		t.Fatalf("message over the user limit allowed, retry after %v", retryAfter)
+	}
+
+	// The connection still has the token the user limit kept it from spending
+	if ok, _ := allowAll(TypeGetPool, now, conn); !ok {
+		t.Error("connection token taken by a rejected message")
+	}
+}
+
+func TestUserLimitersEvictIdleUsers(t *testing.T) {
+	limiters := NewUserLimiters(map[string]config.RateLimit{"*": {Rate: 1, Burst: 5}})
+	now := time.Now()
+
+	limiters.allow("idle", TypeGetPool, now)
+	limiters.allow("active", TypeGetPool, now.Add(limiters.idleAfter/2))
+	limiters.allow("active", TypeGetPool, now.Add(limiters.idleAfter+time.Second))
+
+	if _, ok := limiters.limiters["idle"]; ok {
+		t.Error("idle user's limiter kept")
+	}
+	if _, ok := limiters.limiters["active"]; !ok {
+		t.Error("active user's limiter evicted")
+	}
+}
+
+func TestMalformedFramesAreRateLimited(t *testing.T) {
+	h := newSessionHandler(t)
+	h.userLimiters = NewUserLimiters(nil)
+	client := newTestClient("default")
+	client.limiter = newRateLimiter(map[string]config.RateLimit{"*": {Rate: 0.001, Burst: 1}})
+	h.registerClient(client)
+	received(t, client)
+
+	for range 3 {
+		h.handleMessage(client, []byte("not json"))
+	}
+
+	messages := received(t, client)
+	if len(messages) != 3 || messages[0].Type != TypeError || messages[1].Type != TypeRateLimited || messages[2].Type != TypeRateLimited {
+		t.Errorf("replies %+v, want one error and then rate_limited", messages)
+	}
+}
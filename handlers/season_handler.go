@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"gacha/models"
+	"gacha/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeasonHandler handles season pass requests
+type SeasonHandler struct {
+	userService   *services.UserService
+	seasonService *services.SeasonService
+	logger        *slog.Logger
+}
+
+// NewSeasonHandler creates a new season pass handler
+func NewSeasonHandler(userService *services.UserService, seasonService *services.SeasonService, logger *slog.Logger) *SeasonHandler {
+	return &SeasonHandler{
+		userService:   userService,
+		seasonService: seasonService,
+		logger:        logger,
+	}
+}
+
+// HandleGetSeason returns the default user's progress on the active season
+func (h *SeasonHandler) HandleGetSeason(c *gin.Context) {
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	c.JSON(http.StatusOK, h.seasonService.Status(user))
+}
+
+// HandleClaimTier grants a reached season tier's reward on a track
+func (h *SeasonHandler) HandleClaimTier(c *gin.Context) {
+	var req models.SeasonClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+
+	status, err := h.seasonService.ClaimTier(ctx, user, req.Tier, req.Track)
+	switch {
+	case errors.Is(err, services.ErrUnknownTier), errors.Is(err, services.ErrUnknownTrack):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tier not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+	c.JSON(http.StatusOK, status)
+}
+
+// HandleUnlockPremium buys the active season's premium track
+func (h *SeasonHandler) HandleUnlockPremium(c *gin.Context) {
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+
+	err := h.seasonService.UnlockPremium(ctx, user)
+	var limitErr *services.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		respondLimitError(c, err)
+		return
+	case errors.Is(err, services.ErrInsufficientCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient currency"})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+	c.JSON(http.StatusOK, h.seasonService.Status(user))
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"gacha/models"
+)
+
+const (
+	sessionBufferSize = 256             // Events kept for replay per session
+	sessionTTL        = 2 * time.Minute // How long a detached session can be resumed
+)
+
+// Session is a resumable stream of events delivered to one client at a time
+type Session struct {
+	id         string
+	username   string
+	mu         sync.Mutex
+	client     *Client    // Attached client, nil while disconnected
+	seq        uint64     // Sequence number of the latest event
+	events     []Outbound // Bounded replay buffer, oldest first
+	detachedAt time.Time
+}
+
+// newSession creates a session with a random identifier
+func newSession(username string) *Session {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+
+	return &Session{
+		id:       hex.EncodeToString(buf),
+		username: username,
+	}
+}
+
+// publish assigns the next sequence number to an event, buffers it and
+// delivers it to the attached client
+func (s *Session) publish(msg Outbound) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	msg.Seq = s.seq
+	s.events = append(s.events, msg)
+	if len(s.events) > sessionBufferSize {
+		s.events = s.events[len(s.events)-sessionBufferSize:]
+	}
+
+	if s.client != nil {
+		s.client.enqueue(msg)
+	}
+}
+
+// covers reports whether every event after lastSeq is still buffered,
+// called with mu held
+func (s *Session) covers(lastSeq uint64) bool {
+	if lastSeq > s.seq {
+		return false
+	}
+	if lastSeq == s.seq {
+		return true
+	}
+	return len(s.events) > 0 && s.events[0].Seq <= lastSeq+1
+}
+
+// attach binds a newly connected client to the session
+func (s *Session) attach(client *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attachLocked(client, s.seq, false)
+}
+
+// resume binds a reconnecting client and replays the events it missed,
+// returning false if some of them have already been evicted
+func (s *Session) resume(client *Client, lastSeq uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.covers(lastSeq) {
+		return false
+	}
+	s.attachLocked(client, lastSeq, true)
+	return true
+}
+
+// attachLocked binds a client, acknowledges the session and replays events
+// after lastSeq, called with mu held
+func (s *Session) attachLocked(client *Client, lastSeq uint64, resumed bool) {
+	if s.client != nil && s.client != client {
+		// A stale connection still holds the session; it is replaced
+		s.client.disconnect()
+	}
+	s.client = client
+	s.detachedAt = time.Time{}
+
+	client.enqueue(Outbound{Type: TypeSession, Data: s.info(resumed)})
+	for _, msg := range s.events {
+		if msg.Seq > lastSeq {
+			client.enqueue(msg)
+		}
+	}
+}
+
+// acknowledge tells the attached client which session it is bound to
+func (s *Session) acknowledge(resumed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil {
+		s.client.enqueue(Outbound{Type: TypeSession, Data: s.info(resumed)})
+	}
+}
+
+// detach unbinds a client from the session, starting its expiry
+func (s *Session) detach(client *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == client {
+		s.client = nil
+		s.detachedAt = time.Now()
+	}
+}
+
+// expired reports whether a detached session can no longer be resumed
+func (s *Session) expired(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.client == nil && now.Sub(s.detachedAt) > sessionTTL
+}
+
+// info describes the session for an acknowledgement, called with mu held
+func (s *Session) info(resumed bool) models.SessionResponse {
+	return models.SessionResponse{
+		SessionID: s.id,
+		Seq:       s.seq,
+		Resumed:   resumed,
+	}
+}
+
+// enqueue encodes a message for the client and queues it for writing. It
+// never blocks, as sessions publish with their lock held: a client whose send
+// buffer is full is marked saturated, drops every later message rather than
+// deliver the stream with a gap, and is disconnected once the writer has
+// flushed its queue or sendTimeout passes. Its session keeps the events so
+// they can be replayed on resume.
+func (c *Client) enqueue(msg Outbound) {
+	if c.saturated.Load() {
+		c.metrics.MessageDropped()
+		return
+	}
+
+	msgBytes, err := c.codec.Encode(msg)
+	if err != nil {
+		c.log.Error("failed to encode message", "type", msg.Type, "error", err)
+		return
+	}
+
+	select {
+	case c.send <- msgBytes:
+	default:
+		c.metrics.MessageDropped()
+		c.saturate()
+	}
+}
+
+// saturate marks the client saturated and schedules its disconnection
+func (c *Client) saturate() {
+	if c.saturated.Swap(true) {
+		return
+	}
+	c.log.Warn("send buffer saturated, disconnecting")
+	time.AfterFunc(c.sendTimeout, c.disconnect)
+}
+
+// disconnect closes the client's connection, ending its read and write loops
+func (c *Client) disconnect() {
+	c.closeOnce.Do(func() {
+		c.conn.Close()
+	})
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gacha/config"
+	"gacha/internal/testenv"
+	"gacha/logging"
+	"gacha/metrics"
+	"gacha/models"
+
+	"github.com/gorilla/websocket"
+)
+
+// newSessionHandler returns a handler with only what session management and
+// a resync need
+func newSessionHandler(t *testing.T) *WebSocketHandler {
+	env := testenv.New(t, config.LoadConfig())
+	return &WebSocketHandler{
+		userService:    env.Users,
+		economyService: env.Economy,
+		sessions:       make(map[string]*Session),
+		userSessions:   make(map[string]map[*Session]bool),
+		clients:        make(map[*websocket.Conn]*Client),
+	}
+}
+
+// newTestClient returns a client without a connection, whose messages stay queued
+func newTestClient(username string) *Client {
+	return &Client{
+		id:          logging.NewID(),
+		username:    username,
+		codec:       jsonCodec{},
+		send:        make(chan []byte, 2*sessionBufferSize),
+		sendTimeout: time.Second,
+		metrics:     metrics.New(),
+		log:         logging.Discard(),
+	}
+}
+
+// received drains the messages queued for a client
+func received(t *testing.T, client *Client) []WebSocketMessage {
+	t.Helper()
+	var messages []WebSocketMessage
+	for {
+		select {
+		case frame := <-client.send:
+			var msg WebSocketMessage
+			if err := json.Unmarshal(frame, &msg); err != nil {
+				t.Fatal(err)
+			}
+			messages = append(messages, msg)
+		default:
+			return messages
+		}
+	}
+}
+
+// sessionAck decodes the session acknowledgement a batch of messages starts with
+func sessionAck(t *testing.T, messages []WebSocketMessage) models.SessionResponse {
+	t.Helper()
+	if len(messages) == 0 || messages[0].Type != TypeSession {
+		t.Fatalf("messages %+v do not start with a session acknowledgement", messages)
+	}
+	var ack models.SessionResponse
+	if err := json.Unmarshal([]byte(messages[0].Data), &ack); err != nil {
+		t.Fatal(err)
+	}
+	return ack
+}
+
+// disconnected connects a client, publishes count events to its session
+// and disconnects it, returning the detached session
+func disconnected(t *testing.T, h *WebSocketHandler, username string, count int) *Session {
+	t.Helper()
+	client := newTestClient(username)
+	h.registerClient(client)
+	session := client.session
+	for range count {
+		session.publish(Outbound{Type: TypeUserInfo})
+	}
+	received(t, client)
+	h.unregisterClient(client)
+	return session
+}
+
+func TestResumeReplaysEventsMissedWhileDisconnected(t *testing.T) {
+	h := newSessionHandler(t)
+	previous := disconnected(t, h, "default", 3)
+
+	// Published while no client was attached
+	previous.publish(Outbound{Type: TypeUserInfo})
+	previous.publish(Outbound{Type: TypeInventoryUpdate})
+
+	client := newTestClient("default")
+	h.registerClient(client)
+	fresh := client.session
+	received(t, client)
+
+	h.handleResume(context.Background(), client, models.ResumeRequest{SessionID: previous.id, LastSeq: 3})
+
+	messages := received(t, client)
+	if ack := sessionAck(t, messages); !ack.Resumed || ack.SessionID != previous.id || ack.Seq != 5 {
+		t.Errorf("acknowledged %+v", ack)
+	}
+	if len(messages) != 3 || messages[1].Seq != 4 || messages[2].Seq != 5 || messages[2].Type != TypeInventoryUpdate {
+		t.Errorf("replayed %+v, want events 4 and 5", messages[1:])
+	}
+	if client.session != previous || h.sessions[fresh.id] != nil {
+		t.Error("client not moved onto its previous session")
+	}
+
+	// Later events continue the resumed sequence
+	previous.publish(Outbound{Type: TypeUserInfo})
+	if messages := received(t, client); len(messages) != 1 || messages[0].Seq != 6 {
+		t.Errorf("after resume received %+v", messages)
+	}
+}
+
+func TestResumeResyncsOnceBufferOverflows(t *testing.T) {
+	h := newSessionHandler(t)
+	previous := disconnected(t, h, "default", 1)
+	for range sessionBufferSize + 1 {
+		previous.publish(Outbound{Type: TypeUserInfo})
+	}
+
+	client := newTestClient("default")
+	h.registerClient(client)
+	fresh := client.session
+	received(t, client)
+
+	h.handleResume(context.Background(), client, models.ResumeRequest{SessionID: previous.id, LastSeq: 1})
+
+	messages := received(t, client)
+	if ack := sessionAck(t, messages); ack.Resumed || ack.SessionID != fresh.id {
+		t.Errorf("acknowledged %+v after event 2 was evicted", ack)
+	}
+	if len(messages) != 3 || messages[1].Type != TypeUserInfo || messages[2].Type != TypeInventory {
+		t.Errorf("resync sent %+v, want user_info and inventory", messages[1:])
+	}
+	if client.session != fresh {
+		t.Error("client moved onto a session it could not resume")
+	}
+}
+
+func TestResumeResyncsAfterTTL(t *testing.T) {
+	h := newSessionHandler(t)
+	previous := disconnected(t, h, "default", 2)
+	previous.mu.Lock()
+	previous.detachedAt = time.Now().Add(-sessionTTL - time.Second)
+	previous.mu.Unlock()
+
+	client := newTestClient("default")
+	h.registerClient(client)
+	received(t, client)
+
+	h.handleResume(context.Background(), client, models.ResumeRequest{SessionID: previous.id, LastSeq: 2})
+
+	if ack := sessionAck(t, received(t, client)); ack.Resumed {
+		t.Errorf("expired session resumed: %+v", ack)
+	}
+	if h.sessions[previous.id] != nil {
+		t.Error("expired session not pruned")
+	}
+}
+
+func TestResumeRejectsAnotherUsersSession(t *testing.T) {
+	h := newSessionHandler(t)
+	victim := disconnected(t, h, "victim", 1)
+	victim.publish(Outbound{Type: TypeUserInfo})
+
+	client := newTestClient("default")
+	h.registerClient(client)
+	own := client.session
+	received(t, client)
+
+	h.handleResume(context.Background(), client, models.ResumeRequest{SessionID: victim.id, LastSeq: 0})
+
+	messages := received(t, client)
+	if ack := sessionAck(t, messages); ack.Resumed || ack.SessionID != own.id {
+		t.Errorf("acknowledged %+v for another user's session", ack)
+	}
+	if len(messages) != 3 || messages[1].Type != TypeUserInfo || messages[2].Type != TypeInventory {
+		t.Errorf("sent %+v, want a resync of the client's own state", messages[1:])
+	}
+	if client.session != own || h.sessions[victim.id] != victim || victim.client != nil {
+		t.Error("another user's session was taken over")
+	}
+}
+
+func TestPublishDoesNotBlockOnSaturatedClient(t *testing.T) {
+	session := newSession("default")
+	client := newTestClient("default")
+	client.send = make(chan []byte, 1)
+	client.sendTimeout = time.Hour
+	session.attach(client) // The acknowledgement fills the buffer
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 3 {
+			session.publish(Outbound{Type: TypeUserInfo})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked on a full send buffer")
+	}
+	if !client.saturated.Load() {
+		t.Fatal("client not marked saturated")
+	}
+
+	// Once saturated, nothing more is queued, so the stream has no gap
+	received(t, client)
+	session.publish(Outbound{Type: TypeUserInfo})
+	if messages := received(t, client); len(messages) != 0 {
+		t.Errorf("saturated client received %+v", messages)
+	}
+	if len(session.events) != 4 {
+		t.Errorf("session kept %d events for replay, want 4", len(session.events))
+	}
+}
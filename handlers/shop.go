@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"gacha/models"
+	"gacha/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleGetShop returns the shop's offers and the items they sell
+func (h *UserHandler) HandleGetShop(c *gin.Context) {
+	c.JSON(http.StatusOK, models.ShopResponse{
+		Offers: models.GetShopOffers(),
+		Items:  models.GetItems(),
+	})
+}
+
+// HandleBuy buys a shop offer for the default user
+func (h *UserHandler) HandleBuy(c *gin.Context) {
+	var req models.ShopPurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+	_, err := h.economyService.Buy(ctx, user, req.OfferID)
+	var limitErr *services.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		respondLimitError(c, err)
+		return
+	case errors.Is(err, services.ErrInsufficientCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient currency"})
+		return
+	case errors.Is(err, services.ErrUnknownOffer):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+	c.JSON(http.StatusOK, shopPurchaseResponse(h.economyService.Snapshot(user)))
+}
+
+// shopPurchaseResponse builds the balances returned after a shop purchase from a snapshot of the user
+func shopPurchaseResponse(user models.User) models.ShopPurchaseResponse {
+	return models.ShopPurchaseResponse{
+		Currency: user.Currency,
+		Tickets:  user.PullTickets(),
+	}
+}
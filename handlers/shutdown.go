@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+)
+
+// drainGroup tracks in-flight operations and refuses new ones once stopped
+type drainGroup struct {
+	mu       sync.Mutex
+	active   int
+	draining bool
+	idle     chan struct{} // Closed once draining and no operations remain
+}
+
+// newDrainGroup creates a new drain group
+func newDrainGroup() *drainGroup {
+	return &drainGroup{
+		idle: make(chan struct{}),
+	}
+}
+
+// Begin registers an operation, returning false once the group is stopped
+func (g *drainGroup) Begin() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.draining {
+		return false
+	}
+	g.active++
+	return true
+}
+
+// End marks an operation registered with Begin as finished
+func (g *drainGroup) End() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.active--
+	if g.draining && g.active == 0 {
+		close(g.idle)
+	}
+}
+
+// Stop refuses new operations without waiting for active ones
+func (g *drainGroup) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.draining {
+		return
+	}
+	g.draining = true
+	if g.active == 0 {
+		close(g.idle)
+	}
+}
+
+// Wait blocks until all active operations have finished or ctx is done
+func (g *drainGroup) Wait(ctx context.Context) error {
+	select {
+	case <-g.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Drain refuses new operations and waits for active ones to finish
+func (g *drainGroup) Drain(ctx context.Context) error {
+	g.Stop()
+	return g.Wait(ctx)
+}
+
+// Shutdown stops accepting connections, waits for in-flight pulls, purchases
+// and claims to commit, then sends every client a close frame and waits for
+// them to disconnect
+func (h *WebSocketHandler) Shutdown(ctx context.Context) error {
+	h.conns.Stop()
+
+	err := h.transactions.Drain(ctx)
+	h.shutdownOnce.Do(func() {
+		close(h.shutdown)
+	})
+
+	if waitErr := h.conns.Wait(ctx); err == nil {
+		err = waitErr
+	}
+	return err
+}
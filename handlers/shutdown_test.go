@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"gacha/config"
+	"gacha/internal/testenv"
+)
+
+func TestShutdownRefusesEconomyMessages(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	h := NewWebSocketHandler(env.Gacha, env.Users, env.Economy, env.Daily, env.Missions, env.Seasons,
+		NewUserLimiters(env.Config.RateLimit.PerUser), env.Metrics, env.Logger, env.Config.RateLimit)
+	client := newTestClient("default")
+	client.limiter = newRateLimiter(env.Config.RateLimit.PerConnection)
+	user := env.Users.GetDefaultUser(context.Background())
+	balance := user.Currency
+
+	h.transactions.Stop()
+	frames := []string{
+		`{"type":"add_currency","data":"{\"amount\":100}"}`,
+		`{"type":"single_pull"}`,
+		`{"type":"claim_mission","data":"{\"missionId\":\"daily_pull_1\"}"}`,
+		`{"type":"claim_season_tier","data":"{\"tier\":1,\"track\":\"free\"}"}`,
+	}
+	for _, frame := range frames {
+		h.handleMessage(client, []byte(frame))
+		messages := received(t, client)
+		if len(messages) != 1 || messages[0].Error != "Server is shutting down" {
+			t.Errorf("%s while shutting down sent %+v", frame, messages)
+		}
+	}
+
+	// Not even the daily login reward is granted
+	if user.Currency != balance || user.Logins.LastClaim != "" {
+		t.Errorf("balance %d, last daily claim %q after shutdown began", user.Currency, user.Logins.LastClaim)
+	}
+}
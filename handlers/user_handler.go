@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
 
 	"gacha/models"
@@ -11,36 +13,38 @@ import (
 
 // UserHandler handles user-related requests
 type UserHandler struct {
-	userService *services.UserService
+	userService    *services.UserService
+	limitService   *services.LimitService
+	economyService *services.EconomyService
+	dailyService   *services.DailyService
+	logger         *slog.Logger
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userService *services.UserService) *UserHandler {
+func NewUserHandler(userService *services.UserService, limitService *services.LimitService, economyService *services.EconomyService, dailyService *services.DailyService, logger *slog.Logger) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:    userService,
+		limitService:   limitService,
+		economyService: economyService,
+		dailyService:   dailyService,
+		logger:         logger,
 	}
 }
 
 // HandleGetUserInfo returns user information
 func (h *UserHandler) HandleGetUserInfo(c *gin.Context) {
-	user := h.userService.GetDefaultUser()
-
-	response := models.UserInfoResponse{
-		Username:  user.Username,
-		Currency:  user.Currency,
-		PityCount: user.PityCount,
-	}
-
-	c.JSON(http.StatusOK, response)
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	c.JSON(http.StatusOK, userInfoResponse(h.economyService.Snapshot(user)))
 }
 
 // HandleGetInventory returns user inventory
 func (h *UserHandler) HandleGetInventory(c *gin.Context) {
-	user := h.userService.GetDefaultUser()
+	user := h.userService.GetDefaultUser(c.Request.Context())
 
+	snapshot := h.economyService.Snapshot(user)
 	response := models.InventoryResponse{
-		Inventory: user.Inventory,
-		Count:     len(user.Inventory),
+		Inventory: snapshot.Inventory,
+		Count:     len(snapshot.Inventory),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -50,17 +54,100 @@ func (h *UserHandler) HandleGetInventory(c *gin.Context) {
 func (h *UserHandler) HandleAddCurrency(c *gin.Context) {
 	var req models.AddCurrencyRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindV1(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	user := h.userService.GetDefaultUser()
-	user.AddCurrency(req.Amount)
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+	if err := h.economyService.Purchase(ctx, user, req.Amount); err != nil {
+		if errors.Is(err, services.ErrInvalidAmount) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must be positive"})
+			return
+		}
+		respondLimitError(c, err)
+		return
+	}
+
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
 
 	response := models.CurrencyResponse{
-		Currency: user.Currency,
+		Currency: h.economyService.Snapshot(user).Currency,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// HandleRegister registers a user in an age bracket
+func (h *UserHandler) HandleRegister(c *gin.Context) {
+	var req models.RegisterRequest
+
+	if err := bindV1(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Username is required"})
+		return
+	}
+	if !h.limitService.ValidBracket(req.AgeBracket) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid age bracket"})
+		return
+	}
+	if h.userService.GetUser(c.Request.Context(), req.Username) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Username already taken"})
+		return
+	}
+
+	user := h.userService.CreateUser(c.Request.Context(), req.Username, req.AgeBracket)
+	c.JSON(http.StatusOK, userInfoResponse(h.economyService.Snapshot(user)))
+}
+
+// HandleGetLimits returns the user's spending limits and usage this month
+func (h *UserHandler) HandleGetLimits(c *gin.Context) {
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	c.JSON(http.StatusOK, h.limitService.Status(user))
+}
+
+// HandleSetLimits sets the user's self-imposed monthly limit
+func (h *UserHandler) HandleSetLimits(c *gin.Context) {
+	var req models.SetLimitRequest
+
+	if err := bindV1(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.SelfLimit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must not be negative"})
+		return
+	}
+
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	h.limitService.SetSelfLimit(user, req.SelfLimit)
+
+	c.JSON(http.StatusOK, h.limitService.Status(user))
+}
+
+// limitErrorResponse describes a spending limit rejection
+func limitErrorResponse(err error) models.LimitErrorResponse {
+	response := models.LimitErrorResponse{
+		Error: err.Error(),
+		Code:  models.ErrorCodeSpendingLimit,
+	}
+
+	var limitErr *services.LimitError
+	if errors.As(err, &limitErr) {
+		details := limitDetails(limitErr)
+		response.Limit = details.Limit
+		response.Used = details.Used
+		response.Requested = details.Requested
+		response.ResetsAt = details.ResetsAt
+	}
+	return response
+}
+
+// respondLimitError writes a spending limit rejection
+func respondLimitError(c *gin.Context, err error) {
+	c.JSON(http.StatusForbidden, limitErrorResponse(err))
+}
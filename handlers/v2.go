@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"gacha/fairness"
+	"gacha/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The /api/v2 handlers share their logic with v1 but validate request bodies
+// through binding tags and report every failure in the models.ErrorResponse
+// envelope, with 402 for insufficient currency, 404 for unknown resources,
+// 409 for conflicts and 429 when the user's rate limit is exhausted.
+
+// HandlePullV2 handles a v2 pull request: a single pull without a body, or
+// count pulls that may stop early on a condition
+func (h *GachaHandler) HandlePullV2(c *gin.Context) {
+	req, ok := bindPullRequest(c, func(err error) {
+		respondBindError(c, err)
+	})
+	if !ok {
+		return
+	}
+
+	msgType := TypePull
+	if req.Count <= 1 && req.StopOn == nil {
+		msgType = TypeSinglePull
+	}
+	h.handlePullV2(c, msgType, func(ctx context.Context, user *models.User) (models.GachaResult, error) {
+		return h.economyService.PullMany(ctx, user, req)
+	})
+}
+
+// HandleTenPullV2 handles a v2 ten pull request
+func (h *GachaHandler) HandleTenPullV2(c *gin.Context) {
+	h.handlePullV2(c, TypeTenPull, func(ctx context.Context, user *models.User) (models.GachaResult, error) {
+		return h.economyService.Pull(ctx, user, models.PullTypeTen)
+	})
+}
+
+// handlePullV2 performs a pull for the default user, sharing the user's
+// WebSocket rate limit for the equivalent message type
+func (h *GachaHandler) handlePullV2(c *gin.Context, msgType string, pull func(context.Context, *models.User) (models.GachaResult, error)) {
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+
+	if ok, retryAfter := h.userLimiters.allow(user.Username, msgType, time.Now()); !ok {
+		respondRateLimited(c, msgType, retryAfter)
+		return
+	}
+
+	result, err := pull(ctx, user)
+	if err != nil {
+		respondEconomyError(c, err)
+		return
+	}
+
+	h.userService.NotifyUpdate(ctx, user.Username, newCharacters(result))
+	c.JSON(http.StatusOK, result)
+}
+
+// HandleCalculatorV2 returns the odds of obtaining a target character
+func (h *GachaHandler) HandleCalculatorV2(c *gin.Context) {
+	h.calculator(c, respondV2)
+}
+
+// HandleDisclosureV2 returns the probability disclosure of a banner
+func (h *GachaHandler) HandleDisclosureV2(c *gin.Context) {
+	h.disclosure(c, respondV2)
+}
+
+// HandleAddCurrencyV2 purchases currency for the user
+func (h *UserHandler) HandleAddCurrencyV2(c *gin.Context) {
+	var req models.AddCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+	if err := h.economyService.Purchase(ctx, user, req.Amount); err != nil {
+		respondEconomyError(c, err)
+		return
+	}
+
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+	c.JSON(http.StatusOK, models.CurrencyResponse{Currency: h.economyService.Snapshot(user).Currency})
+}
+
+// HandleRegisterV2 registers a user in an age bracket
+func (h *UserHandler) HandleRegisterV2(c *gin.Context) {
+	var req models.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if !h.limitService.ValidBracket(req.AgeBracket) {
+		respondError(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Request validation failed", []models.FieldError{{
+			Field:  "ageBracket",
+			Rule:   "bracket",
+			Reason: "is not a known age bracket",
+		}})
+		return
+	}
+	if h.userService.GetUser(c.Request.Context(), req.Username) != nil {
+		respondError(c, http.StatusConflict, models.ErrorCodeUsernameTaken, "Username already taken", nil)
+		return
+	}
+
+	user := h.userService.CreateUser(c.Request.Context(), req.Username, req.AgeBracket)
+	c.JSON(http.StatusOK, userInfoResponse(h.economyService.Snapshot(user)))
+}
+
+// HandleBuyV2 buys a shop offer for the default user
+func (h *UserHandler) HandleBuyV2(c *gin.Context) {
+	var req models.ShopPurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+	if _, err := h.economyService.Buy(ctx, user, req.OfferID); err != nil {
+		respondEconomyError(c, err)
+		return
+	}
+
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+	c.JSON(http.StatusOK, shopPurchaseResponse(h.economyService.Snapshot(user)))
+}
+
+// HandleClaimMissionV2 grants the reward of a completed mission
+func (h *MissionHandler) HandleClaimMissionV2(c *gin.Context) {
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+
+	status, err := h.missionService.Claim(ctx, user, c.Param("id"))
+	if err != nil {
+		respondEconomyError(c, err)
+		return
+	}
+
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+	c.JSON(http.StatusOK, status)
+}
+
+// HandleClaimTierV2 grants a reached season tier's reward on a track
+func (h *SeasonHandler) HandleClaimTierV2(c *gin.Context) {
+	var req models.SeasonClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+
+	status, err := h.seasonService.ClaimTier(ctx, user, req.Tier, req.Track)
+	if err != nil {
+		respondEconomyError(c, err)
+		return
+	}
+
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+	c.JSON(http.StatusOK, status)
+}
+
+// HandleUnlockPremiumV2 buys the active season's premium track
+func (h *SeasonHandler) HandleUnlockPremiumV2(c *gin.Context) {
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	ctx := userContext(h.logger, c, user)
+
+	if err := h.seasonService.UnlockPremium(ctx, user); err != nil {
+		respondEconomyError(c, err)
+		return
+	}
+
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+	c.JSON(http.StatusOK, h.seasonService.Status(user))
+}
+
+// HandleSetLimitsV2 sets the user's self-imposed monthly limit
+func (h *UserHandler) HandleSetLimitsV2(c *gin.Context) {
+	var req models.SetLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	h.limitService.SetSelfLimit(user, req.SelfLimit)
+
+	c.JSON(http.StatusOK, h.limitService.Status(user))
+}
+
+// RequireFairness rejects v2 fairness requests while provably fair mode is disabled
+func (h *FairnessHandler) RequireFairness(c *gin.Context) {
+	if !h.fairnessService.Enabled() {
+		respondError(c, http.StatusConflict, models.ErrorCodeFairnessDisabled, "Provably fair mode is disabled", nil)
+		return
+	}
+	c.Next()
+}
+
+// HandleSetClientSeedV2 changes the client seed used for future pulls
+func (h *FairnessHandler) HandleSetClientSeedV2(c *gin.Context) {
+	var req models.ClientSeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	user := h.userService.GetDefaultUser(c.Request.Context())
+	c.JSON(http.StatusOK, h.fairnessService.SetClientSeed(user.Username, req.ClientSeed))
+}
+
+// HandleVerifyV2 recomputes a pull from a revealed server seed
+func (h *FairnessHandler) HandleVerifyV2(c *gin.Context) {
+	var req models.VerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	response, err := fairness.Verify(c.Request.Context(), req)
+	if errors.Is(err, fairness.ErrSeedMismatch) {
+		respondError(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, err.Error(), []models.FieldError{{
+			Field:  "serverSeedHash",
+			Rule:   "match",
+			Reason: "does not match serverSeed",
+		}})
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, err.Error(), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gacha/config"
+	"gacha/internal/testenv"
+	"gacha/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newV2Router serves the v1 and v2 pull, user and fairness routes for the
+// default user, with per-user limits from rateLimits
+func newV2Router(t *testing.T, rateLimits map[string]config.RateLimit) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	env := testenv.New(t, config.LoadConfig())
+	userLimiters := NewUserLimiters(rateLimits)
+
+	gachaHandler := NewGachaHandler(env.Gacha, env.Users, env.Economy, userLimiters, env.Logger, env.Config.Gacha)
+	userHandler := NewUserHandler(env.Users, env.Limits, env.Economy, env.Daily, env.Logger)
+	fairnessHandler := NewFairnessHandler(env.Fairness, env.Users, env.Logger)
+
+	r := gin.New()
+	r.POST("/api/gacha/pull-ten", gachaHandler.HandleTenPull)
+	r.GET("/api/gacha/calculator", gachaHandler.HandleCalculator)
+	r.POST("/api/user/add-currency", userHandler.HandleAddCurrency)
+	r.POST("/api/user/register", userHandler.HandleRegister)
+	r.PUT("/api/user/limits", userHandler.HandleSetLimits)
+	r.POST("/api/fairness/verify", fairnessHandler.HandleVerify)
+	v2 := r.Group("/api/v2")
+	v2.POST("/gacha/pull", gachaHandler.HandlePullV2)
+	v2.POST("/gacha/pull-ten", gachaHandler.HandleTenPullV2)
+	v2.GET("/gacha/calculator", gachaHandler.HandleCalculatorV2)
+	v2.POST("/user/add-currency", userHandler.HandleAddCurrencyV2)
+	v2.POST("/user/register", userHandler.HandleRegisterV2)
+	v2.POST("/fairness/rotate", fairnessHandler.RequireFairness, fairnessHandler.HandleRotate)
+	v2.POST("/fairness/verify", fairnessHandler.HandleVerifyV2)
+	return r
+}
+
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+	return recorder
+}
+
+func decodeError(t *testing.T, recorder *httptest.ResponseRecorder) models.APIError {
+	t.Helper()
+	var response struct {
+		Error models.APIError `json:"error"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode %q: %v", recorder.Body.String(), err)
+	}
+	return response.Error
+}
+
+func TestV2ValidationErrors(t *testing.T) {
+	r := newV2Router(t, nil)
+
+	tests := []struct {
+		path  string
+		body  string
+		field string
+		rule  string
+	}{
+		{"/api/v2/user/add-currency", `{}`, "amount", "required"},
+		{"/api/v2/user/add-currency", `{"amount": -5}`, "amount", "gt"},
+		{"/api/v2/user/add-currency", `{"amount": "lots"}`, "amount", "type"},
+		{"/api/v2/fairness/verify", `{"serverSeed": "s", "pullType": "multi", "count": 2000000000}`, "count", "lte"},
+	}
+	for _, tt := range tests {
+		recorder := serve(r, http.MethodPost, tt.path, tt.body)
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", tt.body, recorder.Code)
+			continue
+		}
+
+		apiErr := decodeError(t, recorder)
+		data, _ := json.Marshal(apiErr.Details)
+		var fields []models.FieldError
+		json.Unmarshal(data, &fields)
+		if apiErr.Code != models.ErrorCodeInvalidRequest || len(fields) != 1 || fields[0].Field != tt.field || fields[0].Rule != tt.rule {
+			t.Errorf("%s: got %+v, want %s failing %s", tt.body, apiErr, tt.field, tt.rule)
+		}
+	}
+}
+
+func TestV1KeepsItsValidation(t *testing.T) {
+	r := newV2Router(t, nil)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+		error  string
+	}{
+		{http.MethodPost, "/api/user/register", `{"ageBracket": "adult"}`, http.StatusBadRequest, "Username is required"},
+		{http.MethodPut, "/api/user/limits", `{"selfLimit": -1}`, http.StatusBadRequest, "Limit must not be negative"},
+		{http.MethodPost, "/api/user/add-currency", `{"amount": -5}`, http.StatusBadRequest, "Amount must be positive"},
+		{http.MethodGet, "/api/gacha/calculator?pulls=100001", "", http.StatusBadRequest, "Invalid pulls"},
+		{http.MethodPost, "/api/fairness/verify", `{"serverSeed": "s", "pullType": "multi", "count": 2000}`, http.StatusBadRequest, "multi pull count must be between 1 and 1000"},
+		// Only v2 bounds the client seed
+		{http.MethodPost, "/api/fairness/verify", `{"serverSeed": "s", "pullType": "ten", "clientSeed": "` + strings.Repeat("c", 100) + `"}`, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		recorder := serve(r, tt.method, tt.path, tt.body)
+		if recorder.Code != tt.status {
+			t.Errorf("%s %s: status %d, want %d: %s", tt.method, tt.path, recorder.Code, tt.status, recorder.Body)
+			continue
+		}
+		if tt.error == "" {
+			continue
+		}
+		var response struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil || response.Error != tt.error {
+			t.Errorf("%s %s: body %s, want error %q", tt.method, tt.path, recorder.Body, tt.error)
+		}
+	}
+}
+
+func TestV2ErrorStatuses(t *testing.T) {
+	r := newV2Router(t, map[string]config.RateLimit{
+		TypeSinglePull: {Rate: 0.001, Burst: 1},
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"unknown banner", http.MethodGet, "/api/v2/gacha/calculator?banner=missing", "", http.StatusNotFound, models.ErrorCodeNotFound},
+		{"too many calculator pulls", http.MethodGet, "/api/v2/gacha/calculator?pulls=100001", "", http.StatusBadRequest, models.ErrorCodeInvalidRequest},
+		{"most calculator pulls", http.MethodGet, "/api/v2/gacha/calculator?pulls=100000", "", http.StatusOK, ""},
+		{"username taken", http.MethodPost, "/api/v2/user/register", `{"username": "default", "ageBracket": "adult"}`, http.StatusConflict, models.ErrorCodeUsernameTaken},
+		{"fairness disabled", http.MethodPost, "/api/v2/fairness/rotate", "", http.StatusConflict, models.ErrorCodeFairnessDisabled},
+		{"first pull", http.MethodPost, "/api/v2/gacha/pull", "", http.StatusOK, ""},
+		{"rate limited", http.MethodPost, "/api/v2/gacha/pull", "", http.StatusTooManyRequests, models.ErrorCodeRateLimited},
+	}
+	for _, tt := range tests {
+		recorder := serve(r, tt.method, tt.path, tt.body)
+		if recorder.Code != tt.status {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, recorder.Code, tt.status, recorder.Body)
+			continue
+		}
+		if tt.code != "" {
+			if code := decodeError(t, recorder).Code; code != tt.code {
+				t.Errorf("%s: code %q, want %q", tt.name, code, tt.code)
+			}
+		}
+		if tt.status == http.StatusTooManyRequests && recorder.Header().Get("Retry-After") == "" {
+			t.Errorf("%s: missing Retry-After", tt.name)
+		}
+	}
+}
+
+func TestV2InsufficientCurrency(t *testing.T) {
+	r := newV2Router(t, nil)
+
+	// The default user's 10000 currency covers six ten pulls
+	for i := 0; i < 6; i++ {
+		if recorder := serve(r, http.MethodPost, "/api/v2/gacha/pull-ten", ""); recorder.Code != http.StatusOK {
+			t.Fatalf("pull %d: status %d: %s", i, recorder.Code, recorder.Body)
+		}
+	}
+
+	recorder := serve(r, http.MethodPost, "/api/v2/gacha/pull-ten", "")
+	if recorder.Code != http.StatusPaymentRequired || decodeError(t, recorder).Code != models.ErrorCodeInsufficientCurrency {
+		t.Errorf("v2: status %d: %s", recorder.Code, recorder.Body)
+	}
+
+	// v1 keeps its original status and body
+	recorder = serve(r, http.MethodPost, "/api/gacha/pull-ten", "")
+	if recorder.Code != http.StatusBadRequest || recorder.Body.String() != `{"error":"Insufficient currency"}` {
+		t.Errorf("v1: status %d: %s", recorder.Code, recorder.Body)
+	}
+}
@@ -1,20 +1,33 @@
 package handlers
 
 import (
-	"encoding/json"
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"gacha/config"
+	"gacha/logging"
+	"gacha/metrics"
 	"gacha/models"
 	"gacha/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer creates a span for each WebSocket message
+var tracer = otel.Tracer("gacha/handlers")
+
 var upgrader = websocket.Upgrader{
+	Subprotocols: []string{CodecJSON, CodecMsgPack},
 	CheckOrigin: func(r *http.Request) bool {
 		return true // Allow all origins in development
 	},
@@ -24,89 +37,284 @@ var upgrader = websocket.Upgrader{
 const (
 	TypeSinglePull   = "single_pull"
 	TypeTenPull      = "ten_pull"
+	TypePull         = "pull" // Count pulls with an optional stop condition
 	TypeGetUserInfo  = "get_user_info"
 	TypeGetInventory = "get_inventory"
 	TypeGetPool      = "get_pool"
 	TypeAddCurrency  = "add_currency"
+	TypeGetMissions  = "get_missions"
+	TypeClaimMission = "claim_mission"
+	TypeGetSeason    = "get_season"
+	TypeClaimTier    = "claim_season_tier"
+	TypeResume       = "resume"
 
 	// Response types
-	TypeGachaResult    = "gacha_result"
-	TypeUserInfo       = "user_info"
-	TypeInventory      = "inventory"
-	TypePoolInfo       = "pool_info"
-	TypeCurrencyUpdate = "currency_update"
-	TypeError          = "error"
-	TypePing           = "ping"
-	TypePong           = "pong"
+	TypeGachaResult     = "gacha_result"
+	TypeUserInfo        = "user_info"
+	TypeInventory       = "inventory"
+	TypePoolInfo        = "pool_info"
+	TypeCurrencyUpdate  = "currency_update"
+	TypeInventoryUpdate = "inventory_update"
+	TypeSession         = "session"
+	TypeError           = "error"
+	TypeRateLimited     = "rate_limited"
+	TypeDailyReward     = "daily_reward"
+	TypeMissions        = "missions"
+	TypeMissionClaimed  = "mission_claimed"
+	TypeSeason          = "season"
+	TypeTierClaimed     = "season_tier_claimed"
+	TypePing            = "ping"
+	TypePong            = "pong"
 )
 
-// WebSocketMessage represents a message sent over WebSocket
+// WebSocketMessage represents a message sent over WebSocket with the JSON codec
 type WebSocketMessage struct {
 	Type  string `json:"type"`
+	Seq   uint64 `json:"seq,omitempty"`
 	Data  string `json:"data,omitempty"`
 	Error string `json:"error,omitempty"`
 }
 
 // WebSocketHandler handles WebSocket connections
 type WebSocketHandler struct {
-	gachaService *services.GachaService
-	userService  *services.UserService
-	clients      map[*websocket.Conn]*Client
-	clientsMu    sync.RWMutex
+	gachaService   *services.GachaService
+	userService    *services.UserService
+	economyService *services.EconomyService
+	dailyService   *services.DailyService
+	missionService *services.MissionService
+	seasonService  *services.SeasonService
+	metrics        *metrics.Metrics
+	logger         *slog.Logger
+	clients        map[*websocket.Conn]*Client
+	sessions       map[string]*Session          // Sessions indexed by ID
+	userSessions   map[string]map[*Session]bool // Sessions indexed by username
+	clientsMu      sync.RWMutex
+	rateLimit      config.RateLimitConfig
+	userLimiters   *UserLimiters // Shared with HTTP requests
+	conns          *drainGroup   // Open connections
+	transactions   *drainGroup   // Economy transactions that have not yet committed
+	shutdown       chan struct{} // Closed to make writers send a close frame
+	shutdownOnce   sync.Once
 }
 
 // Client represents a connected WebSocket client
 type Client struct {
-	conn     *websocket.Conn
-	username string
-	send     chan []byte
+	id          string
+	conn        *websocket.Conn
+	username    string
+	codec       Codec
+	session     *Session // Guarded by WebSocketHandler.clientsMu
+	limiter     *rateLimiter
+	send        chan []byte
+	sendTimeout time.Duration // How long a saturated client has to flush its queue before disconnecting
+	saturated   atomic.Bool   // Set once a message is dropped on a full send buffer
+	dailyDue    atomic.Int64  // Unix nanoseconds from which a message claims the daily reward again
+	metrics     *metrics.Metrics
+	log         *slog.Logger      // Annotated with the connection and user IDs
+	upgrade     trace.SpanContext // Span of the upgrade request, linked from message spans
+	closeOnce   sync.Once
 }
 
 // NewWebSocketHandler creates a new WebSocket handler
-func NewWebSocketHandler(gachaService *services.GachaService, userService *services.UserService) *WebSocketHandler {
-	return &WebSocketHandler{
-		gachaService: gachaService,
-		userService:  userService,
-		clients:      make(map[*websocket.Conn]*Client),
+func NewWebSocketHandler(gachaService *services.GachaService, userService *services.UserService, economyService *services.EconomyService, dailyService *services.DailyService, missionService *services.MissionService, seasonService *services.SeasonService, userLimiters *UserLimiters, metrics *metrics.Metrics, logger *slog.Logger, rateLimit config.RateLimitConfig) *WebSocketHandler {
+	h := &WebSocketHandler{
+		gachaService:   gachaService,
+		userService:    userService,
+		economyService: economyService,
+		dailyService:   dailyService,
+		missionService: missionService,
+		seasonService:  seasonService,
+		metrics:        metrics,
+		logger:         logger,
+		clients:        make(map[*websocket.Conn]*Client),
+		sessions:       make(map[string]*Session),
+		userSessions:   make(map[string]map[*Session]bool),
+		rateLimit:      rateLimit,
+		userLimiters:   userLimiters,
+		conns:          newDrainGroup(),
+		transactions:   newDrainGroup(),
+		shutdown:       make(chan struct{}),
 	}
+
+	// Push state changes from any channel to every session of the user
+	userService.Subscribe(h.broadcastUserUpdate)
+
+	return h
 }
 
 // HandleWebSocket handles WebSocket connection
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
+	if !h.conns.Begin() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Server is shutting down"})
+		return
+	}
+
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
-		log.Printf("Failed to upgrade connection: %v", err)
+		h.conns.End()
+		h.logger.Warn("failed to upgrade connection", logging.RequestIDKey, logging.RequestID(c), "error", err)
 		return
 	}
 
 	client := &Client{
-		conn:     conn,
-		username: "default", // Can be extended to get from query params
-		send:     make(chan []byte, 256),
+		id:          logging.NewID(),
+		conn:        conn,
+		username:    "default", // Can be extended to get from query params
+		codec:       codecFor(conn.Subprotocol()),
+		limiter:     newRateLimiter(h.rateLimit.PerConnection),
+		send:        make(chan []byte, h.rateLimit.SendBufferSize),
+		sendTimeout: h.rateLimit.SendTimeout,
+		metrics:     h.metrics,
+		upgrade:     trace.SpanContextFromContext(c.Request.Context()),
+	}
+	client.log = h.logger.With(logging.RequestIDKey, logging.RequestID(c), logging.ConnIDKey, client.id)
+	if user := h.userService.GetUser(c.Request.Context(), client.username); user != nil {
+		client.log = client.log.With(logging.UserIDKey, user.ID)
 	}
 
-	h.clientsMu.Lock()
-	h.clients[conn] = client
-	h.clientsMu.Unlock()
+	h.registerClient(client)
 
-	log.Printf("New WebSocket client connected: %s", conn.RemoteAddr())
+	client.log.Info("client connected", "remote_addr", conn.RemoteAddr().String(), "codec", client.codec.Name())
 
 	// Start goroutines for reading and writing
 	go h.receiveMessages(client)
 	go h.writeMessages(client)
 
-	// Send initial user info
-	h.sendUserInfo(client)
+	// Send initial user info, then any daily reward the connection claims
+	h.sendUserInfo(c.Request.Context(), client)
+	h.claimDaily(logging.NewContext(c.Request.Context(), client.log), client)
+}
+
+// ClientCount returns the number of active WebSocket connections
+func (h *WebSocketHandler) ClientCount() int {
+	h.clientsMu.RLock()
+	defer h.clientsMu.RUnlock()
+	return len(h.clients)
+}
+
+// registerClient adds a client to the connection index and attaches it to a new session
+func (h *WebSocketHandler) registerClient(client *Client) {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+
+	h.pruneSessions(time.Now())
+
+	session := newSession(client.username)
+	h.sessions[session.id] = session
+	if h.userSessions[client.username] == nil {
+		h.userSessions[client.username] = make(map[*Session]bool)
+	}
+	h.userSessions[client.username][session] = true
+
+	h.clients[client.conn] = client
+	client.session = session
+	session.attach(client)
+}
+
+// unregisterClient removes a client from the connection index and detaches its session
+func (h *WebSocketHandler) unregisterClient(client *Client) {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+
+	delete(h.clients, client.conn)
+	client.session.detach(client)
+}
+
+// removeSession drops a session from the session indexes, called with clientsMu held
+func (h *WebSocketHandler) removeSession(session *Session) {
+	delete(h.sessions, session.id)
+	if sessions, ok := h.userSessions[session.username]; ok {
+		delete(sessions, session)
+		if len(sessions) == 0 {
+			delete(h.userSessions, session.username)
+		}
+	}
+}
+
+// pruneSessions drops detached sessions past their TTL, called with clientsMu held
+func (h *WebSocketHandler) pruneSessions(now time.Time) {
+	for _, session := range h.sessions {
+		if session.expired(now) {
+			h.removeSession(session)
+		}
+	}
+}
+
+// sessionOf returns the session a client is attached to
+func (h *WebSocketHandler) sessionOf(client *Client) *Session {
+	h.clientsMu.RLock()
+	defer h.clientsMu.RUnlock()
+	return client.session
+}
+
+// sessionsForUser returns every live or resumable session of a user
+func (h *WebSocketHandler) sessionsForUser(username string) []*Session {
+	h.clientsMu.RLock()
+	defer h.clientsMu.RUnlock()
+
+	sessions := make([]*Session, 0, len(h.userSessions[username]))
+	for session := range h.userSessions[username] {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// broadcastUserUpdate pushes updated user info and inventory deltas to every session of a user
+func (h *WebSocketHandler) broadcastUserUpdate(ctx context.Context, update services.UserUpdate) {
+	user := h.userService.GetUser(ctx, update.Username)
+	if user == nil {
+		return
+	}
+	snapshot := h.economyService.Snapshot(user)
+
+	for _, session := range h.sessionsForUser(update.Username) {
+		session.publish(Outbound{Type: TypeUserInfo, Data: userInfoResponse(snapshot)})
+
+		if len(update.NewCharacters) > 0 {
+			session.publish(Outbound{Type: TypeInventoryUpdate, Data: models.InventoryUpdateResponse{
+				Added: update.NewCharacters,
+				Count: len(snapshot.Inventory),
+			}})
+		}
+		if update.DailyReward != nil {
+			session.publish(Outbound{Type: TypeDailyReward, Data: update.DailyReward})
+		}
+	}
+}
+
+// handleResume moves a reconnecting client onto its previous session and
+// replays missed events, falling back to a full resync
+func (h *WebSocketHandler) handleResume(ctx context.Context, client *Client, req models.ResumeRequest) {
+	h.clientsMu.Lock()
+	h.pruneSessions(time.Now())
+
+	previous, ok := h.sessions[req.SessionID]
+	current := client.session
+	if !ok || previous == current || previous.username != client.username || !previous.resume(client, req.LastSeq) {
+		h.clientsMu.Unlock()
+		h.resync(ctx, client)
+		return
+	}
+
+	current.detach(client)
+	h.removeSession(current)
+	client.session = previous
+	h.clientsMu.Unlock()
+}
+
+// resync sends the client its full state when missed events cannot be replayed
+func (h *WebSocketHandler) resync(ctx context.Context, client *Client) {
+	h.sessionOf(client).acknowledge(false)
+	h.sendUserInfo(ctx, client)
+	h.sendInventory(ctx, client)
 }
 
 // receiveMessages handles incoming messages from the client
 func (h *WebSocketHandler) receiveMessages(client *Client) {
 	defer func() {
-		h.clientsMu.Lock()
-		delete(h.clients, client.conn)
-		h.clientsMu.Unlock()
+		h.unregisterClient(client)
 		client.conn.Close()
-		log.Printf("Client disconnected: %s", client.conn.RemoteAddr())
+		client.log.Info("client disconnected")
 	}()
 
 	client.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
@@ -119,7 +327,7 @@ func (h *WebSocketHandler) receiveMessages(client *Client) {
 		_, message, err := client.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
+				client.log.Warn("unexpected close", "error", err)
 			}
 			break
 		}
@@ -134,6 +342,7 @@ func (h *WebSocketHandler) writeMessages(client *Client) {
 	defer func() {
 		ticker.Stop()
 		client.conn.Close()
+		h.conns.End()
 	}()
 
 	for {
@@ -145,158 +354,361 @@ func (h *WebSocketHandler) writeMessages(client *Client) {
 				return
 			}
 
-			if err := client.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			if err := client.conn.WriteMessage(client.codec.FrameType(), message); err != nil {
 				return
 			}
+			if client.saturated.Load() && len(client.send) == 0 {
+				return // Flushed; the client resumes to recover what was dropped
+			}
 
 		case <-ticker.C:
 			client.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if err := client.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
+
+		case <-h.shutdown:
+			h.closeForShutdown(client)
+			return
 		}
 	}
 }
 
-// handleMessage processes incoming messages
+// closeForShutdown flushes queued messages and sends a close frame to the client
+func (h *WebSocketHandler) closeForShutdown(client *Client) {
+flush:
+	for {
+		select {
+		case message, ok := <-client.send:
+			if !ok {
+				return
+			}
+			client.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := client.conn.WriteMessage(client.codec.FrameType(), message); err != nil {
+				return
+			}
+		default:
+			break flush
+		}
+	}
+
+	client.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseServiceRestart, "server restarting")
+	client.conn.WriteMessage(websocket.CloseMessage, closeMsg)
+}
+
+// handleMessage processes incoming messages, each in its own trace linked to
+// the connection's upgrade request
 func (h *WebSocketHandler) handleMessage(client *Client, message []byte) {
-	var msg WebSocketMessage
-	if err := json.Unmarshal(message, &msg); err != nil {
+	ctx, span := tracer.Start(context.Background(), "ws.message",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithLinks(trace.Link{SpanContext: client.upgrade}),
+		trace.WithAttributes(
+			attribute.String("ws.conn_id", client.id),
+			attribute.String("ws.codec", client.codec.Name()),
+		),
+	)
+	defer span.End()
+	ctx = logging.NewContext(ctx, client.log)
+
+	// Frames are rate limited before anything else, malformed ones under the
+	// default limit, so that no frame is processed for free
+	msgType, payload, err := client.codec.Decode(message)
+	if err != nil {
+		msgType = invalidMessageType
+	}
+	if !h.allowMessage(client, msgType) {
+		return
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid message format")
+		client.log.DebugContext(ctx, "invalid message", "error", err)
 		h.sendError(client, "Invalid message format")
 		return
 	}
+	span.SetName("ws." + msgType)
+	span.SetAttributes(attribute.String("ws.message_type", msgType))
+	client.log.DebugContext(ctx, "message received", "type", msgType)
+
+	if msgType != TypePing {
+		h.claimDaily(ctx, client)
+	}
 
-	switch msg.Type {
+	switch msgType {
 	case TypePing:
 		h.sendPong(client)
 
 	case TypeSinglePull:
-		h.handleSinglePull(client)
+		h.track(ctx, client, h.handleSinglePull)
 
 	case TypeTenPull:
-		h.handleTenPull(client)
+		h.track(ctx, client, h.handleTenPull)
+
+	case TypePull:
+		var req models.PullRequest
+		if err := client.codec.DecodePayload(payload, &req); err != nil {
+			h.sendError(client, "Invalid pull payload")
+			return
+		}
+		h.track(ctx, client, func(ctx context.Context, client *Client) {
+			h.handlePullMany(ctx, client, req)
+		})
 
 	case TypeGetUserInfo:
-		h.sendUserInfo(client)
+		h.sendUserInfo(ctx, client)
 
 	case TypeGetInventory:
-		h.sendInventory(client)
+		h.sendInventory(ctx, client)
 
 	case TypeGetPool:
 		h.sendPoolInfo(client)
 
+	case TypeResume:
+		var req models.ResumeRequest
+		if err := client.codec.DecodePayload(payload, &req); err != nil {
+			h.sendError(client, "Invalid resume payload")
+			return
+		}
+		h.handleResume(ctx, client, req)
+
 	case TypeAddCurrency:
 		var req models.AddCurrencyRequest
-		h.handleAddCurrency(client, req.Amount)
+		if err := client.codec.DecodePayload(payload, &req); err != nil {
+			h.sendError(client, "Invalid add_currency payload")
+			return
+		}
+		h.track(ctx, client, func(ctx context.Context, client *Client) {
+			h.handleAddCurrency(ctx, client, req.Amount)
+		})
+
+	case TypeGetMissions:
+		h.sendMissions(ctx, client)
+
+	case TypeClaimMission:
+		var req models.MissionClaimRequest
+		if err := client.codec.DecodePayload(payload, &req); err != nil {
+			h.sendError(client, "Invalid claim_mission payload")
+			return
+		}
+		h.track(ctx, client, func(ctx context.Context, client *Client) {
+			h.handleClaimMission(ctx, client, req.MissionID)
+		})
+
+	case TypeGetSeason:
+		h.sendSeason(ctx, client)
+
+	case TypeClaimTier:
+		var req models.SeasonClaimRequest
+		if err := client.codec.DecodePayload(payload, &req); err != nil {
+			h.sendError(client, "Invalid claim_season_tier payload")
+			return
+		}
+		h.track(ctx, client, func(ctx context.Context, client *Client) {
+			h.handleClaimTier(ctx, client, req)
+		})
 
 	default:
 		h.sendError(client, "Unknown message type")
 	}
 }
 
+// track runs a message that changes the user's balances, inventory or
+// progress so that shutdown waits for it to commit
+func (h *WebSocketHandler) track(ctx context.Context, client *Client, transaction func(context.Context, *Client)) {
+	if !h.transactions.Begin() {
+		h.sendError(client, "Server is shutting down")
+		return
+	}
+	defer h.transactions.End()
+
+	transaction(ctx, client)
+}
+
 // handleSinglePull processes single pull request
-func (h *WebSocketHandler) handleSinglePull(client *Client) {
-	user := h.userService.GetUser(client.username)
+func (h *WebSocketHandler) handleSinglePull(ctx context.Context, client *Client) {
+	h.handlePull(ctx, client, models.PullTypeSingle)
+}
+
+// handleTenPull processes ten pull request
+func (h *WebSocketHandler) handleTenPull(ctx context.Context, client *Client) {
+	h.handlePull(ctx, client, models.PullTypeTen)
+}
+
+// claimDaily grants the client's user their daily login reward if it is
+// unclaimed, pushing daily_reward to every session of the user. A connection
+// claims once, then again only after the daily reset, rather than taking the
+// daily service's lock on every message. Once shutdown begins the claim is
+// left for the next connection.
+func (h *WebSocketHandler) claimDaily(ctx context.Context, client *Client) {
+	if time.Now().UnixNano() < client.dailyDue.Load() {
+		return
+	}
+	if !h.transactions.Begin() {
+		return
+	}
+	defer h.transactions.End()
+	client.dailyDue.Store(h.dailyService.NextReset().UnixNano())
+
+	user := h.userService.GetUser(ctx, client.username)
 	if user == nil {
-		h.sendError(client, "User not found")
 		return
 	}
 
-	if !user.DeductCurrency(160) {
-		h.sendError(client, "Insufficient currency")
+	reward, err := h.dailyService.Claim(ctx, user)
+	if err != nil {
+		client.dailyDue.Store(0) // Retried on the next message
+		client.log.ErrorContext(ctx, "failed to claim daily reward", "error", err)
 		return
 	}
+	if reward != nil {
+		h.userService.Notify(ctx, services.UserUpdate{Username: user.Username, DailyReward: reward})
+	}
+}
 
-	char := h.gachaService.PerformSinglePull(user)
-	isNew := user.AddCharacter(char)
+// handlePull performs a pull of the given type for the client's user
+func (h *WebSocketHandler) handlePull(ctx context.Context, client *Client, pullType string) {
+	h.performPull(ctx, client, func(user *models.User) (models.GachaResult, error) {
+		return h.economyService.Pull(ctx, user, pullType)
+	})
+}
+
+// handlePullMany performs count pulls, stopping early on the request's condition
+func (h *WebSocketHandler) handlePullMany(ctx context.Context, client *Client, req models.PullRequest) {
+	h.performPull(ctx, client, func(user *models.User) (models.GachaResult, error) {
+		return h.economyService.PullMany(ctx, user, req)
+	})
+}
+
+// performPull runs a pull for the client's user and sends its result
+func (h *WebSocketHandler) performPull(ctx context.Context, client *Client, pull func(*models.User) (models.GachaResult, error)) {
+	user := h.userService.GetUser(ctx, client.username)
+	if user == nil {
+		h.sendError(client, "User not found")
+		return
+	}
 
-	result := models.GachaResult{
-		Characters: []models.Character{char},
-		IsNew:      []bool{isNew},
-		Timestamp:  time.Now().Unix(),
+	result, err := pull(user)
+	if err != nil {
+		h.sendEconomyError(client, err)
+		return
 	}
 
 	h.sendMessage(client, TypeGachaResult, result)
-	h.sendUserInfo(client)
+	h.userService.NotifyUpdate(ctx, user.Username, newCharacters(result))
 }
 
-// handleTenPull processes ten pull request
-func (h *WebSocketHandler) handleTenPull(client *Client) {
-	user := h.userService.GetUser(client.username)
+// sendMissions sends the user's progress on every mission to client
+func (h *WebSocketHandler) sendMissions(ctx context.Context, client *Client) {
+	user := h.userService.GetUser(ctx, client.username)
 	if user == nil {
 		h.sendError(client, "User not found")
 		return
 	}
 
-	if !user.DeductCurrency(1600) {
-		h.sendError(client, "Insufficient currency")
+	h.sendMessage(client, TypeMissions, h.missionService.List(user))
+}
+
+// handleClaimMission grants the reward of a completed mission
+func (h *WebSocketHandler) handleClaimMission(ctx context.Context, client *Client, missionID string) {
+	user := h.userService.GetUser(ctx, client.username)
+	if user == nil {
+		h.sendError(client, "User not found")
+		return
+	}
+
+	status, err := h.missionService.Claim(ctx, user, missionID)
+	if err != nil {
+		h.sendError(client, err.Error())
+		return
+	}
+
+	h.sendMessage(client, TypeMissionClaimed, status)
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
+}
+
+// sendSeason sends the user's progress on the active season pass to client
+func (h *WebSocketHandler) sendSeason(ctx context.Context, client *Client) {
+	user := h.userService.GetUser(ctx, client.username)
+	if user == nil {
+		h.sendError(client, "User not found")
 		return
 	}
 
-	characters := h.gachaService.PerformTenPull(user)
-	var isNewList []bool
+	h.sendMessage(client, TypeSeason, h.seasonService.Status(user))
+}
 
-	for _, char := range characters {
-		isNew := user.AddCharacter(char)
-		isNewList = append(isNewList, isNew)
+// handleClaimTier grants a reached season tier's reward on a track
+func (h *WebSocketHandler) handleClaimTier(ctx context.Context, client *Client, req models.SeasonClaimRequest) {
+	user := h.userService.GetUser(ctx, client.username)
+	if user == nil {
+		h.sendError(client, "User not found")
+		return
 	}
 
-	result := models.GachaResult{
-		Characters: characters,
-		IsNew:      isNewList,
-		Timestamp:  time.Now().Unix(),
+	status, err := h.seasonService.ClaimTier(ctx, user, req.Tier, req.Track)
+	if err != nil {
+		h.sendError(client, err.Error())
+		return
 	}
 
-	h.sendMessage(client, TypeGachaResult, result)
-	h.sendUserInfo(client)
+	h.sendMessage(client, TypeTierClaimed, status)
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
 }
 
 // handleAddCurrency adds currency to user
-func (h *WebSocketHandler) handleAddCurrency(client *Client, amount int) {
-	user := h.userService.GetUser(client.username)
+func (h *WebSocketHandler) handleAddCurrency(ctx context.Context, client *Client, amount int) {
+	user := h.userService.GetUser(ctx, client.username)
 	if user == nil {
 		h.sendError(client, "User not found")
 		return
 	}
 
-	user.AddCurrency(amount)
+	if err := h.economyService.Purchase(ctx, user, amount); err != nil {
+		h.sendEconomyError(client, err)
+		return
+	}
 
 	response := models.CurrencyResponse{
-		Currency: user.Currency,
+		Currency: h.economyService.Snapshot(user).Currency,
 	}
 
 	h.sendMessage(client, TypeCurrencyUpdate, response)
-	h.sendUserInfo(client)
+	h.userService.NotifyUpdate(ctx, user.Username, nil)
 }
 
 // sendUserInfo sends user information to client
-func (h *WebSocketHandler) sendUserInfo(client *Client) {
-	user := h.userService.GetUser(client.username)
+func (h *WebSocketHandler) sendUserInfo(ctx context.Context, client *Client) {
+	user := h.userService.GetUser(ctx, client.username)
 	if user == nil {
 		h.sendError(client, "User not found")
 		return
 	}
 
-	response := models.UserInfoResponse{
+	h.sendMessage(client, TypeUserInfo, userInfoResponse(h.economyService.Snapshot(user)))
+}
+
+// userInfoResponse builds the user info payload from a snapshot of the user
+func userInfoResponse(user models.User) models.UserInfoResponse {
+	return models.UserInfoResponse{
 		Username:  user.Username,
 		Currency:  user.Currency,
 		PityCount: user.PityCount,
+		Tickets:   user.PullTickets(),
 	}
-
-	h.sendMessage(client, TypeUserInfo, response)
 }
 
 // sendInventory sends user inventory to client
-func (h *WebSocketHandler) sendInventory(client *Client) {
-	user := h.userService.GetUser(client.username)
+func (h *WebSocketHandler) sendInventory(ctx context.Context, client *Client) {
+	user := h.userService.GetUser(ctx, client.username)
 	if user == nil {
 		h.sendError(client, "User not found")
 		return
 	}
 
+	snapshot := h.economyService.Snapshot(user)
 	response := models.InventoryResponse{
-		Inventory: user.Inventory,
-		Count:     len(user.Inventory),
+		Inventory: snapshot.Inventory,
+		Count:     len(snapshot.Inventory),
 	}
 
 	h.sendMessage(client, TypeInventory, response)
@@ -304,83 +716,39 @@ func (h *WebSocketHandler) sendInventory(client *Client) {
 
 // sendPoolInfo sends pool information to client
 func (h *WebSocketHandler) sendPoolInfo(client *Client) {
-	poolInfo := models.PoolInfo{
-		Characters: h.gachaService.GetCharacterPool(),
-		Rates: map[string]string{
-			"ssr": "2%",
-			"sr":  "10%",
-			"r":   "88%",
-		},
-		PitySystem: "Guaranteed SSR at 90 pulls",
-	}
-
-	h.sendMessage(client, TypePoolInfo, poolInfo)
+	h.sendMessage(client, TypePoolInfo, poolInfo(h.gachaService))
 }
 
-// sendMessage sends a typed message to client
+// sendMessage publishes a typed message to the client's session
 func (h *WebSocketHandler) sendMessage(client *Client, msgType string, data interface{}) {
-	msg := WebSocketMessage{
-		Type: msgType,
-	}
-
-	// Marshal data to JSON bytes
-	if data != nil {
-		jsonData, err := json.Marshal(data)
-		if err != nil {
-			log.Printf("Failed to marshal data: %v", err)
-			return
-		}
-		msg.Data = string(jsonData)
-	}
-
-	msgBytes, err := json.Marshal(msg)
-	if err != nil {
-		log.Printf("Failed to marshal message: %v", err)
-		return
-	}
-
-	select {
-	case client.send <- msgBytes:
-	default:
-		log.Printf("Client send channel full, dropping message")
-	}
+	h.sessionOf(client).publish(Outbound{Type: msgType, Data: data})
 }
 
 // sendError sends an error message to client
 func (h *WebSocketHandler) sendError(client *Client, errMsg string) {
-	msg := WebSocketMessage{
-		Type:  TypeError,
-		Error: errMsg,
-	}
-
-	msgBytes, err := json.Marshal(msg)
-	if err != nil {
-		log.Printf("Failed to marshal error: %v", err)
-		return
-	}
+	client.enqueue(Outbound{Type: TypeError, Error: errMsg})
+}
 
-	select {
-	case client.send <- msgBytes:
+// sendEconomyError reports a failed pull or purchase to client, with the
+// details of a spending limit rejection
+func (h *WebSocketHandler) sendEconomyError(client *Client, err error) {
+	var limitErr *services.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		response := limitErrorResponse(err)
+		client.enqueue(Outbound{Type: TypeError, Error: response.Error, Data: response})
+	case errors.Is(err, services.ErrInsufficientCurrency):
+		h.sendError(client, "Insufficient currency")
+	case errors.Is(err, services.ErrInsufficientTickets):
+		h.sendError(client, "Insufficient pull tickets")
+	case errors.Is(err, services.ErrInvalidAmount):
+		h.sendError(client, "Amount must be positive")
 	default:
-		log.Printf("Client send channel full, dropping error message")
+		h.sendError(client, err.Error())
 	}
 }
 
 // sendPong sends a pong response
 func (h *WebSocketHandler) sendPong(client *Client) {
-	msg := WebSocketMessage{
-		Type: TypePong,
-	}
-
-	msgBytes, err := json.Marshal(msg)
-	if err != nil {
-		log.Printf("Failed to marshal pong: %v", err)
-		return
-	}
-
-	select {
-	case client.send <- msgBytes:
-	default:
-		// Pong is not critical, can be dropped
-	}
+	client.enqueue(Outbound{Type: TypePong})
 }
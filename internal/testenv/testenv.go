@@ -0,0 +1,103 @@
+// Package testenv wires the in-memory service graph that handler, route and
+// verification tests run against, as main does for the server.
+package testenv
+
+import (
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"gacha/config"
+	"gacha/engine"
+	"gacha/logging"
+	"gacha/metrics"
+	"gacha/models"
+	"gacha/services"
+)
+
+// Env holds every service of a test server, sharing one event bus
+type Env struct {
+	Config  *config.Config
+	Logger  *slog.Logger
+	Metrics *metrics.Metrics
+	Clock   *Clock // Time source of the limit, daily, mission and season services
+
+	Users    *services.UserService
+	Gacha    *services.GachaService
+	Fairness *services.FairnessService
+	Limits   *services.LimitService
+	Audit    *services.AuditService
+	Events   *services.EventBus
+	Economy  *services.EconomyService
+	Daily    *services.DailyService
+	Missions *services.MissionService
+	Seasons  *services.SeasonService
+}
+
+// Clock reports the current time until a test sets it, and the set time after
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Now returns the set time, or the current time when none is set
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.now.IsZero() {
+		return time.Now()
+	}
+	return c.now
+}
+
+// Set fixes the time the services see
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance moves the clock forward by d from the time it reports
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// New builds the services from cfg, pulling from a seeded source so that
+// results repeat, keeping the audit log in memory, discarding logs and
+// reading the time from Clock
+func New(t testing.TB, cfg *config.Config) *Env {
+	t.Helper()
+	env := &Env{
+		Config:  cfg,
+		Logger:  logging.Discard(),
+		Metrics: metrics.New(),
+		Clock:   &Clock{},
+		Events:  services.NewEventBus(),
+	}
+
+	var err error
+	env.Users = services.NewUserService(env.Logger)
+	env.Gacha = services.NewGachaServiceWithPool(models.GetCharacterPool(), cfg.Gacha.PityThreshold, engine.NewSeededSource(1))
+	env.Fairness = services.NewFairnessService(cfg.Gacha.ProvablyFair)
+	if env.Limits, err = services.NewLimitService(cfg.Limits, env.Logger); err != nil {
+		t.Fatal(err)
+	}
+	if env.Audit, err = services.NewAuditService("", env.Logger); err != nil {
+		t.Fatal(err)
+	}
+	env.Economy = services.NewEconomyService(env.Gacha, env.Fairness, env.Limits, env.Audit, env.Events, env.Metrics, cfg.Gacha, env.Logger)
+	if env.Daily, err = services.NewDailyService(cfg.Daily, env.Events, env.Economy, env.Logger); err != nil {
+		t.Fatal(err)
+	}
+	if env.Missions, err = services.NewMissionService(cfg.Daily.Timezone, env.Events, env.Economy, env.Logger); err != nil {
+		t.Fatal(err)
+	}
+	env.Seasons = services.NewSeasonService(cfg.Season, env.Events, env.Economy, env.Logger)
+
+	env.Limits.SetClock(env.Clock.Now)
+	env.Daily.SetClock(env.Clock.Now)
+	env.Missions.SetClock(env.Clock.Now)
+	env.Seasons.SetClock(env.Clock.Now)
+	return env
+}
@@ -1,36 +1,119 @@
 package main
 
 import (
-	"math/rand"
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"gacha/config"
+	"gacha/engine"
 	"gacha/handlers"
+	"gacha/logging"
+	"gacha/metrics"
+	"gacha/models"
 	"gacha/routes"
 	"gacha/services"
+	"gacha/tracing"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// Initialize random seed
-	rand.Seed(time.Now().UnixNano())
-
 	// Load configuration
 	cfg := config.LoadConfig()
+	if path := os.Getenv("GACHA_CONFIG"); path != "" {
+		fileCfg, err := config.LoadConfigFile(path)
+		if err != nil {
+			slog.Error("failed to load config", "path", path, "error", err)
+			os.Exit(1)
+		}
+		cfg = fileCfg
+	}
+
+	// Initialize logging
+	logger, err := logging.New(cfg.Log)
+	if err != nil {
+		slog.Error("invalid logging config", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	if err := cfg.Validate(); err != nil {
+		logger.Error("invalid config", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		logger.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize metrics
+	m := metrics.New()
 
 	// Initialize services
-	userService := services.NewUserService()
-	gachaService := services.NewGachaService()
+	userService := services.NewUserService(logger)
+	random := engine.NewCryptoSource()
+	if cfg.Gacha.Seed != 0 {
+		logger.Warn("using deterministic RNG", "seed", cfg.Gacha.Seed)
+		random = engine.NewSeededSource(cfg.Gacha.Seed)
+	}
+	gachaService := services.NewGachaServiceWithPool(models.GetCharacterPool(), cfg.Gacha.PityThreshold, random)
+	gachaService.SetObserver(m.PullObserver(models.DefaultBanner().ID))
+	fairnessService := services.NewFairnessService(cfg.Gacha.ProvablyFair)
+	limitService, err := services.NewLimitService(cfg.Limits, logger)
+	if err != nil {
+		logger.Error("failed to initialize spending limits", "error", err)
+		os.Exit(1)
+	}
+	auditService, err := services.NewAuditService(cfg.Audit.Path, logger)
+	if err != nil {
+		logger.Error("failed to open audit log", "path", cfg.Audit.Path, "error", err)
+		os.Exit(1)
+	}
+	defer auditService.Close()
+	events := services.NewEventBus()
+	economyService := services.NewEconomyService(gachaService, fairnessService, limitService, auditService, events, m, cfg.Gacha, logger)
+	dailyService, err := services.NewDailyService(cfg.Daily, events, economyService, logger)
+	if err != nil {
+		logger.Error("failed to initialize daily rewards", "error", err)
+		os.Exit(1)
+	}
+	missionService, err := services.NewMissionService(cfg.Daily.Timezone, events, economyService, logger)
+	if err != nil {
+		logger.Error("failed to initialize missions", "error", err)
+		os.Exit(1)
+	}
+	seasonService := services.NewSeasonService(cfg.Season, events, economyService, logger)
+	userLimiters := handlers.NewUserLimiters(cfg.RateLimit.PerUser)
 
 	// Initialize handlers
-	gachaHandler := handlers.NewGachaHandler(gachaService, userService)
-	userHandler := handlers.NewUserHandler(userService)
-	wsHandler := handlers.NewWebSocketHandler(gachaService, userService)
+	gachaHandler := handlers.NewGachaHandler(gachaService, userService, economyService, userLimiters, logger, cfg.Gacha)
+	userHandler := handlers.NewUserHandler(userService, limitService, economyService, dailyService, logger)
+	fairnessHandler := handlers.NewFairnessHandler(fairnessService, userService, logger)
+	wsHandler := handlers.NewWebSocketHandler(gachaService, userService, economyService, dailyService, missionService, seasonService, userLimiters, m, logger, cfg.RateLimit)
+	healthHandler := handlers.NewHealthHandler(cfg, userService, auditService)
+	adminHandler := handlers.NewAdminHandler(userService, limitService, auditService, economyService, cfg.Admin, logger)
+	missionHandler := handlers.NewMissionHandler(userService, missionService, logger)
+	seasonHandler := handlers.NewSeasonHandler(userService, seasonService, logger)
+	docsHandler, err := handlers.NewDocsHandler()
+	if err != nil {
+		logger.Error("failed to render API documentation", "error", err)
+		os.Exit(1)
+	}
+
+	m.TrackConnections(wsHandler.ClientCount)
 
-	// Setup Gin router
-	r := gin.Default()
+	// Setup Gin router, logging requests through the structured logger
+	r := gin.New()
+	r.Use(gin.Recovery(), logging.Middleware(logger), m.Middleware(), tracing.Middleware())
 
 	// Configure CORS
 	r.Use(cors.New(cors.Config{
@@ -44,8 +127,61 @@ func main() {
 	}))
 
 	// Setup routes
-	routes.SetupRoutes(r, gachaHandler, userHandler, wsHandler)
+	routes.SetupRoutes(r, gachaHandler, userHandler, fairnessHandler, wsHandler, healthHandler, adminHandler, missionHandler, seasonHandler, docsHandler, m)
+
+	srv := &http.Server{
+		Addr:         cfg.Server.Port,
+		Handler:      r,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+	}
 
 	// Start server
-	r.Run(cfg.Server.Port)
+	logger.Info("server listening", "addr", cfg.Server.Port)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	// Wait for interrupt or termination signal, or for the server to fail,
+	// which it can only do before Shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	var failure error
+	select {
+	case <-ctx.Done():
+	case failure = <-serveErr:
+	}
+	stop()
+
+	if failure != nil {
+		logger.Error("server failed", "error", failure)
+	} else {
+		logger.Info("shutting down server")
+
+		// Report not-ready and keep serving while load balancers stop routing here
+		healthHandler.BeginDrain()
+		time.Sleep(cfg.Server.DrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// Drain HTTP requests, then let in-flight WebSocket transactions commit and close the clients
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("HTTP shutdown incomplete", "error", err)
+	}
+	if err := wsHandler.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("WebSocket shutdown incomplete", "error", err)
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Warn("trace export incomplete", "error", err)
+	}
+
+	logger.Info("server stopped")
+	if failure != nil {
+		auditService.Close() // os.Exit skips deferred calls
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,193 @@
+// Package metrics exposes Prometheus metrics for pulls, currency, WebSocket
+// connections and HTTP requests.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gacha/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gacha"
+
+// Metrics holds the server's Prometheus collectors
+type Metrics struct {
+	registry        *prometheus.Registry
+	pulls           *prometheus.CounterVec
+	pityTriggers    *prometheus.CounterVec
+	pullDuration    *prometheus.HistogramVec
+	currencySpent   *prometheus.CounterVec
+	currencyGranted *prometheus.CounterVec
+	itemsSpent      *prometheus.CounterVec
+	itemsGranted    *prometheus.CounterVec
+	droppedMessages prometheus.Counter
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+}
+
+// New creates the server's metrics on a fresh registry, along with Go runtime
+// and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		pulls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pulls_total",
+			Help:      "Characters pulled, by banner and rarity.",
+		}, []string{"banner", "rarity"}),
+		pityTriggers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pity_triggers_total",
+			Help:      "SSRs forced by reaching the pity threshold, by banner.",
+		}, []string{"banner"}),
+		pullDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "pull_duration_seconds",
+			Help:      "Time taken to perform a pull, by banner and pull type.",
+			Buckets:   []float64{.00001, .000025, .00005, .0001, .00025, .0005, .001, .0025, .005, .01},
+		}, []string{"banner", "type"}),
+		currencySpent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "currency_spent_total",
+			Help:      "Currency spent, by reason.",
+		}, []string{"reason"}),
+		currencyGranted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "currency_granted_total",
+			Help:      "Currency granted, by source.",
+		}, []string{"source"}),
+		itemsSpent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "items_spent_total",
+			Help:      "Items consumed, by reason and item.",
+		}, []string{"reason", "item"}),
+		itemsGranted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "items_granted_total",
+			Help:      "Items granted, by source and item.",
+		}, []string{"source", "item"}),
+		droppedMessages: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "websocket_dropped_messages_total",
+			Help:      "WebSocket messages dropped because a client's send buffer stayed full.",
+		}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests, by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency, by route and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.pulls,
+		m.pityTriggers,
+		m.pullDuration,
+		m.currencySpent,
+		m.currencyGranted,
+		m.itemsSpent,
+		m.itemsGranted,
+		m.droppedMessages,
+		m.httpRequests,
+		m.httpDuration,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// TrackConnections exposes the number of active WebSocket connections, as
+// reported by count on each scrape
+func (m *Metrics) TrackConnections(count func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "websocket_connections",
+		Help:      "Active WebSocket connections.",
+	}, func() float64 {
+		return float64(count())
+	}))
+}
+
+// ObservePull records the characters from a pull and how long it took
+func (m *Metrics) ObservePull(bannerID, pullType string, characters []models.Character, duration time.Duration) {
+	for _, char := range characters {
+		m.pulls.WithLabelValues(bannerID, strconv.Itoa(char.Rarity)).Inc()
+	}
+	m.pullDuration.WithLabelValues(bannerID, pullType).Observe(duration.Seconds())
+}
+
+// CurrencySpent records currency deducted from a user
+func (m *Metrics) CurrencySpent(reason string, amount int) {
+	m.currencySpent.WithLabelValues(reason).Add(float64(amount))
+}
+
+// CurrencyGranted records currency added to a user
+func (m *Metrics) CurrencyGranted(source string, amount int) {
+	m.currencyGranted.WithLabelValues(source).Add(float64(amount))
+}
+
+// ItemsSpent records items consumed by a user
+func (m *Metrics) ItemsSpent(reason, itemID string, quantity int) {
+	m.itemsSpent.WithLabelValues(reason, itemID).Add(float64(quantity))
+}
+
+// ItemsGranted records items added to a user
+func (m *Metrics) ItemsGranted(source, itemID string, quantity int) {
+	m.itemsGranted.WithLabelValues(source, itemID).Add(float64(quantity))
+}
+
+// MessageDropped records a WebSocket message dropped on a full send buffer
+func (m *Metrics) MessageDropped() {
+	m.droppedMessages.Inc()
+}
+
+// PullObserver returns an observer that counts pity triggers on a banner
+func (m *Metrics) PullObserver(bannerID string) *PullObserver {
+	return &PullObserver{pityTriggers: m.pityTriggers.WithLabelValues(bannerID)}
+}
+
+// PullObserver counts pull mechanics reported by a gacha service
+type PullObserver struct {
+	pityTriggers prometheus.Counter
+}
+
+// PityTriggered records an SSR forced by the pity threshold
+func (o *PullObserver) PityTriggered() {
+	o.pityTriggers.Inc()
+}
+
+// Middleware records request counts and latency per Gin route. Requests that
+// match no route are grouped under "unmatched" to bound label cardinality.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+
+		m.httpRequests.WithLabelValues(route, method, strconv.Itoa(c.Writer.Status())).Inc()
+		m.httpDuration.WithLabelValues(route, method).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audited actions
+const (
+	AuditPull        = "pull"
+	AuditGrant       = "grant"
+	AuditPurchase    = "purchase"
+	AuditRefund      = "refund"
+	AuditAdminChange = "admin_change"
+	AuditItemGrant   = "item_grant"
+	AuditShopBuy     = "shop_purchase"
+	AuditSpend       = "spend"
+)
+
+// Actor prefixes, followed by a username or admin name
+const (
+	ActorUserPrefix  = "user:"
+	ActorAdminPrefix = "admin:"
+)
+
+// AuditEntry is one link of the hash-chained audit log. Hash covers every
+// other field, including the previous entry's hash.
+type AuditEntry struct {
+	Seq      uint64          `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Action   string          `json:"action"`
+	Actor    string          `json:"actor"`
+	UserID   int             `json:"userId"`
+	Username string          `json:"username"`
+	Details  json.RawMessage `json:"details,omitempty"`
+	PrevHash string          `json:"prevHash"`
+	Hash     string          `json:"hash"`
+}
+
+// AuditPullDetails describes a pull
+type AuditPullDetails struct {
+	PullType     string         `json:"pullType"`
+	BannerID     string         `json:"bannerId"`
+	Cost         int            `json:"cost"`              // Currency spent
+	Tickets      map[string]int `json:"tickets,omitempty"` // Pull tickets spent instead of currency
+	CharacterIDs []int          `json:"characterIds"`
+	PityAfter    int            `json:"pityAfter"`
+	Balance      int            `json:"balance"`
+}
+
+// AuditCurrencyDetails describes a purchase, grant, refund or spend of currency
+type AuditCurrencyDetails struct {
+	Amount  int    `json:"amount"`
+	Reason  string `json:"reason,omitempty"`
+	Balance int    `json:"balance"`
+}
+
+// AuditItemDetails describes items granted to a user or bought from the shop
+type AuditItemDetails struct {
+	ItemID      string `json:"itemId"`
+	Quantity    int    `json:"quantity"`
+	Source      string `json:"source,omitempty"`  // What granted the items, e.g. admin or a reward
+	OfferID     string `json:"offerId,omitempty"` // Shop offer bought
+	Price       int    `json:"price,omitempty"`   // Currency paid for a shop offer
+	Reason      string `json:"reason,omitempty"`
+	ItemBalance int    `json:"itemBalance"`
+	Balance     int    `json:"balance"`
+}
+
+// AuditChangeDetails describes an admin change to a user field
+type AuditChangeDetails struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// AuditFilter selects audit entries; zero fields match everything
+type AuditFilter struct {
+	Username string
+	Actor    string
+	Action   string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// AuditQueryResponse represents the audit entries matching a query
+type AuditQueryResponse struct {
+	Entries []AuditEntry `json:"entries"`
+	Count   int          `json:"count"`
+}
+
+// AdminCurrencyRequest represents an admin grant or refund of currency
+type AdminCurrencyRequest struct {
+	Username string `json:"username"`
+	Amount   int    `json:"amount"`
+	Reason   string `json:"reason"`
+}
+
+// AdminUserUpdateRequest represents an admin change to a user
+type AdminUserUpdateRequest struct {
+	AgeBracket string `json:"ageBracket"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Banner represents a gacha banner: a character pool with featured characters
+type Banner struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Characters  []Character `json:"characters"`
+	FeaturedIDs []int       `json:"featuredIds"`
+}
+
+// DefaultBanner returns the standard banner over the global character pool
+func DefaultBanner() Banner {
+	return Banner{
+		ID:          "standard",
+		Name:        "Standard",
+		Characters:  GetCharacterPool(),
+		FeaturedIDs: []int{3}, // Syndra
+	}
+}
+
+// IsFeatured checks if a character is featured on the banner
+func (b *Banner) IsFeatured(charID int) bool {
+	for _, id := range b.FeaturedIDs {
+		if id == charID {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the banner can be pulled from: it has characters with
+// positive rates, at least one SSR for pity, and featured characters in its pool
+func (b *Banner) Validate() error {
+	if b.ID == "" {
+		return errors.New("banner has no ID")
+	}
+	if len(b.Characters) == 0 {
+		return fmt.Errorf("banner %s has no characters", b.ID)
+	}
+
+	hasSSR := false
+	for _, char := range b.Characters {
+		if char.Rate <= 0 {
+			return fmt.Errorf("banner %s: character %d has non-positive rate", b.ID, char.ID)
+		}
+		if char.Rarity == 5 {
+			hasSSR = true
+		}
+	}
+	if !hasSSR {
+		return fmt.Errorf("banner %s has no SSR for pity to grant", b.ID)
+	}
+
+	for _, id := range b.FeaturedIDs {
+		if _, ok := b.FindCharacter(strconv.Itoa(id)); !ok {
+			return fmt.Errorf("banner %s: featured character %d is not in its pool", b.ID, id)
+		}
+	}
+	return nil
+}
+
+// GetBanners returns every available banner
+func GetBanners() []Banner {
+	return []Banner{DefaultBanner()}
+}
+
+// FindBanner returns the banner with the given ID
+func FindBanner(id string) (Banner, bool) {
+	for _, banner := range GetBanners() {
+		if banner.ID == id {
+			return banner, true
+		}
+	}
+	return Banner{}, false
+}
+
+// FindCharacter returns the banner character matching an ID or a case-insensitive name
+func (b *Banner) FindCharacter(idOrName string) (Character, bool) {
+	for _, char := range b.Characters {
+		if strconv.Itoa(char.ID) == idOrName || strings.EqualFold(char.Name, idOrName) {
+			return char, true
+		}
+	}
+	return Character{}, false
+}
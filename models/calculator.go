@@ -0,0 +1,23 @@
+package models
+
+// CalculatorPoint is the number of pulls, and their cost, needed to reach a probability
+type CalculatorPoint struct {
+	Probability float64 `json:"probability"`
+	Pulls       int     `json:"pulls"`
+	Cost        int     `json:"cost"`
+}
+
+// CalculatorResponse represents the odds of obtaining a target character
+type CalculatorResponse struct {
+	BannerID      string            `json:"bannerId"`
+	Target        Character         `json:"target"`
+	Pity          int               `json:"pity"`
+	Guaranteed    bool              `json:"guaranteed"`
+	Pulls         int               `json:"pulls"`
+	Probability   float64           `json:"probability"` // Chance of the target within Pulls
+	Cost          int               `json:"cost"`        // Currency cost of Pulls
+	ExpectedPulls float64           `json:"expectedPulls"`
+	ExpectedCost  float64           `json:"expectedCost"`
+	Milestones    []CalculatorPoint `json:"milestones"`
+	MaxPulls      int               `json:"maxPulls,omitempty"` // Pulls that make the target certain, omitted if never certain
+}
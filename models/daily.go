@@ -0,0 +1,31 @@
+package models
+
+// LoginCalendar tracks the days a user claimed a daily login reward
+type LoginCalendar struct {
+	LastClaim     string `json:"lastClaim"` // Calendar day, e.g. "2025-01-31"
+	Streak        int    `json:"streak"`    // Consecutive days claimed, ending on LastClaim
+	LongestStreak int    `json:"longestStreak"`
+	Month         string `json:"month"` // Calendar month of Days, e.g. "2025-01"
+	Days          []int  `json:"days"`  // Days of Month claimed
+	Total         int    `json:"total"` // Days claimed in all
+}
+
+// DailyReward represents a daily login reward granted to a user
+type DailyReward struct {
+	Date   string `json:"date"`   // Calendar day claimed, e.g. "2025-01-31"
+	Streak int    `json:"streak"` // Streak day the reward was for
+	Reward Reward `json:"reward"`
+}
+
+// DailyStatusResponse represents a user's login calendar and upcoming reward
+type DailyStatusResponse struct {
+	Today         string   `json:"today"`
+	Claimed       bool     `json:"claimed"` // Today's reward has been granted
+	Streak        int      `json:"streak"`  // 0 once a day has been missed
+	LongestStreak int      `json:"longestStreak"`
+	Month         string   `json:"month"`
+	Days          []int    `json:"days"`       // Days of Month claimed
+	NextReward    *Reward  `json:"nextReward"` // Granted on the next claim, null when rewards are disabled
+	Rewards       []Reward `json:"rewards"`    // By streak day, repeating after the last
+	ResetsAt      string   `json:"resetsAt"`   // Start of the next calendar day, RFC 3339
+}
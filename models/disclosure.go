@@ -0,0 +1,33 @@
+package models
+
+// ProbabilityRates are the chances of an outcome per pull
+type ProbabilityRates struct {
+	BaseRate       float64 `json:"baseRate"`       // Per pull, from pool weights alone
+	SinglePullRate float64 `json:"singlePullRate"` // Long-run per pull with pity, pulling singly
+	TenPullRate    float64 `json:"tenPullRate"`    // Long-run per pull with pity and the SR guarantee, pulling ten at a time
+}
+
+// CharacterProbability discloses the chance of pulling one character
+type CharacterProbability struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Rarity int    `json:"rarity"`
+	ProbabilityRates
+}
+
+// RarityProbability discloses the chance of pulling any character of a rarity
+type RarityProbability struct {
+	Rarity int `json:"rarity"`
+	ProbabilityRates
+}
+
+// Disclosure represents the per-item probability disclosure of a banner
+type Disclosure struct {
+	BannerID      string                 `json:"bannerId"`
+	Version       string                 `json:"version"` // Changes whenever pool weights or rules change
+	GeneratedAt   string                 `json:"generatedAt"`
+	PityThreshold int                    `json:"pityThreshold"`
+	Rules         []string               `json:"rules"`
+	Rarities      []RarityProbability    `json:"rarities"`
+	Characters    []CharacterProbability `json:"characters"`
+}
@@ -0,0 +1,43 @@
+package models
+
+// Error codes returned in the /api/v2 error envelope
+const (
+	ErrorCodeInvalidRequest       = "invalid_request"
+	ErrorCodeInsufficientCurrency = "insufficient_currency"
+	ErrorCodeInsufficientTickets  = "insufficient_tickets"
+	ErrorCodeNotFound             = "not_found"
+	ErrorCodeUsernameTaken        = "username_taken"
+	ErrorCodeFairnessDisabled     = "fairness_disabled"
+	ErrorCodeRateLimited          = "rate_limited"
+	ErrorCodeInternal             = "internal_error"
+)
+
+// ErrorResponse is the body of every /api/v2 error response
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// APIError describes why a request failed. Details depend on the code: field
+// errors for invalid_request, limit usage for spending_limit_exceeded and the
+// retry delay for rate_limited.
+type APIError struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// FieldError describes a request field that failed validation
+type FieldError struct {
+	Field  string `json:"field"`
+	Rule   string `json:"rule"`
+	Param  string `json:"param,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// LimitDetails describes the spending limit a request would exceed
+type LimitDetails struct {
+	Limit     int    `json:"limit"`
+	Used      int    `json:"used"`
+	Requested int    `json:"requested"`
+	ResetsAt  string `json:"resetsAt"`
+}
@@ -0,0 +1,63 @@
+package models
+
+// Pull types recorded in provably fair proofs
+const (
+	PullTypeSingle = "single"
+	PullTypeTen    = "ten"
+	PullTypeMulti  = "multi" // Sequential single pulls that may stop early
+)
+
+// PullProof carries what is needed to verify a pull once its server seed is revealed
+type PullProof struct {
+	ServerSeedHash string         `json:"serverSeedHash"`
+	ClientSeed     string         `json:"clientSeed"`
+	Nonce          uint64         `json:"nonce"`
+	PityBefore     int            `json:"pityBefore"`
+	PityThreshold  int            `json:"pityThreshold"` // Of the engine that pulled
+	BannerID       string         `json:"bannerId"`      // Banner whose pool was pulled
+	PullType       string         `json:"pullType"`
+	Count          int            `json:"count,omitempty"`  // Pulls requested, for multi pulls
+	StopOn         *StopCondition `json:"stopOn,omitempty"` // For multi pulls
+}
+
+// FairnessCommitment represents the active server seed commitment of a user
+type FairnessCommitment struct {
+	ServerSeedHash string `json:"serverSeedHash"`
+	ClientSeed     string `json:"clientSeed"`
+	NextNonce      uint64 `json:"nextNonce"`
+}
+
+// RevealedSeed represents a retired server seed, revealed on rotation
+type RevealedSeed struct {
+	ServerSeed     string             `json:"serverSeed"`
+	ServerSeedHash string             `json:"serverSeedHash"`
+	ClientSeed     string             `json:"clientSeed"`
+	NoncesUsed     uint64             `json:"noncesUsed"`
+	Next           FairnessCommitment `json:"next"`
+}
+
+// ClientSeedRequest represents request to change the client seed
+type ClientSeedRequest struct {
+	ClientSeed string `json:"clientSeed" binding:"required,max=64"`
+}
+
+// VerifyRequest represents request to recompute a provably fair pull
+type VerifyRequest struct {
+	ServerSeed     string         `json:"serverSeed" binding:"required"`
+	ServerSeedHash string         `json:"serverSeedHash,omitempty"` // Checked against ServerSeed when set
+	ClientSeed     string         `json:"clientSeed"`
+	Nonce          uint64         `json:"nonce"`
+	PityBefore     int            `json:"pityBefore" binding:"gte=0"`
+	PityThreshold  int            `json:"pityThreshold,omitempty" binding:"omitempty,gte=1"` // Defaults to the default threshold
+	BannerID       string         `json:"bannerId,omitempty"`                                // Defaults to the standard banner
+	PullType       string         `json:"pullType" binding:"required,oneof=single ten multi"`
+	Count          int            `json:"count,omitempty" binding:"omitempty,gte=1,lte=1000"` // Required for multi pulls
+	StopOn         *StopCondition `json:"stopOn,omitempty"`
+}
+
+// VerifyResponse represents the recomputed outcome of a provably fair pull
+type VerifyResponse struct {
+	ServerSeedHash string      `json:"serverSeedHash"`
+	Characters     []Character `json:"characters"`
+	PityAfter      int         `json:"pityAfter"`
+}
@@ -1,10 +1,40 @@
 package models
 
+// MaxPullCount bounds every multi pull; the configured maximum may only lower
+// it. It is repeated in the binding tags of requests that carry a count.
+const MaxPullCount = 1000
+
 // GachaResult represents the result of a gacha pull
 type GachaResult struct {
-	Characters []Character `json:"characters"`
-	IsNew      []bool      `json:"isNew"` // Whether each character is new
-	Timestamp  int64       `json:"timestamp"`
+	Characters   []Character    `json:"characters"`
+	IsNew        []bool         `json:"isNew"` // Whether each character is new
+	Timestamp    int64          `json:"timestamp"`
+	Proof        *PullProof     `json:"proof,omitempty"`        // Set in provably fair mode
+	Refunded     int            `json:"refunded,omitempty"`     // Currency returned for pulls skipped by a stop condition
+	StoppedEarly bool           `json:"stoppedEarly,omitempty"` // A stop condition ended the pulls before count
+	TicketsUsed  map[string]int `json:"ticketsUsed,omitempty"`  // Pull tickets spent instead of currency, after refunds
+}
+
+// PullRequest represents request to pull count times, stopping early when
+// the stop condition is met
+type PullRequest struct {
+	Count   int            `json:"count" binding:"omitempty,gte=1,lte=1000"` // 1 when omitted, at most the configured maximum
+	StopOn  *StopCondition `json:"stopOn,omitempty"`
+	Payment string         `json:"payment,omitempty" binding:"omitempty,oneof=auto tickets currency"` // auto when omitted
+}
+
+// StopCondition ends a multi pull after the first character that matches
+type StopCondition struct {
+	Rarity      int `json:"rarity,omitempty" binding:"omitempty,gte=3,lte=5"` // Any character of at least this rarity, e.g. 5 for the first SSR
+	CharacterID int `json:"characterId,omitempty"`                            // This character
+}
+
+// Matches reports whether a pulled character meets the condition
+func (c *StopCondition) Matches(char Character) bool {
+	if c == nil {
+		return false
+	}
+	return (c.Rarity > 0 && char.Rarity >= c.Rarity) || (c.CharacterID > 0 && char.ID == c.CharacterID)
 }
 
 // PoolInfo represents gacha pool information
@@ -16,9 +46,10 @@ type PoolInfo struct {
 
 // UserInfoResponse represents user information for API response
 type UserInfoResponse struct {
-	Username  string `json:"username"`
-	Currency  int    `json:"currency"`
-	PityCount int    `json:"pityCount"`
+	Username  string         `json:"username"`
+	Currency  int            `json:"currency"`
+	PityCount int            `json:"pityCount"`
+	Tickets   map[string]int `json:"tickets"` // Pull ticket ID to quantity held
 }
 
 // InventoryResponse represents user inventory for API response
@@ -29,10 +60,22 @@ type InventoryResponse struct {
 
 // AddCurrencyRequest represents request to add currency
 type AddCurrencyRequest struct {
-	Amount int `json:"amount"`
+	Amount int `json:"amount" binding:"required,gt=0"`
 }
 
 // CurrencyResponse represents currency update response
 type CurrencyResponse struct {
 	Currency int `json:"currency"`
 }
+
+// InventoryUpdateResponse represents characters newly added to a user's inventory
+type InventoryUpdateResponse struct {
+	Added []Character `json:"added"`
+	Count int         `json:"count"`
+}
+
+// RateLimitResponse tells a client when a rate limited message may be retried
+type RateLimitResponse struct {
+	MessageType  string `json:"messageType"`
+	RetryAfterMs int64  `json:"retryAfterMs"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// Health statuses
+const (
+	StatusOK       = "ok"
+	StatusNotReady = "not_ready"
+	StatusDraining = "draining"
+)
+
+// HealthResponse reports that the process is alive
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// ReadinessResponse reports whether the server can take traffic, with the
+// result of each check: "ok" or the reason it failed
+type ReadinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// VersionResponse describes the running build and configuration
+type VersionResponse struct {
+	Commit     string `json:"commit"`
+	BuildTime  string `json:"buildTime"`
+	Modified   bool   `json:"modified"`
+	GoVersion  string `json:"goVersion"`
+	ConfigHash string `json:"configHash"`
+}
@@ -0,0 +1,124 @@
+package models
+
+// Item kinds
+const (
+	ItemKindPullTicket = "pull_ticket" // Pays for one pull instead of currency
+)
+
+// Pull ticket item IDs
+const (
+	ItemGenericTicket  = "ticket_generic"
+	ItemStandardTicket = "ticket_standard"
+)
+
+// Ways to pay for a pull
+const (
+	PaymentAuto     = "auto"     // Tickets when enough are held, currency otherwise
+	PaymentTickets  = "tickets"  // Tickets only
+	PaymentCurrency = "currency" // Currency only
+)
+
+// Item represents a kind of item a user can hold, kept apart from characters
+type Item struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	BannerID string `json:"bannerId,omitempty"` // Banner a ticket is limited to, empty for any banner
+}
+
+// GetItems returns every item kind
+func GetItems() []Item {
+	return []Item{
+		{ID: ItemGenericTicket, Name: "Pull Ticket", Kind: ItemKindPullTicket},
+		{ID: ItemStandardTicket, Name: "Standard Pull Ticket", Kind: ItemKindPullTicket, BannerID: DefaultBanner().ID},
+	}
+}
+
+// FindItem returns the item with the given ID
+func FindItem(id string) (Item, bool) {
+	for _, item := range GetItems() {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// PullTicketsFor returns the tickets accepted by a banner, in the order pulls
+// consume them: the banner's own tickets before generic ones
+func PullTicketsFor(bannerID string) []Item {
+	var specific, generic []Item
+	for _, item := range GetItems() {
+		switch {
+		case item.Kind != ItemKindPullTicket:
+		case item.BannerID == bannerID:
+			specific = append(specific, item)
+		case item.BannerID == "":
+			generic = append(generic, item)
+		}
+	}
+	return append(specific, generic...)
+}
+
+// Reward represents currency and items granted together
+type Reward struct {
+	Currency int            `json:"currency,omitempty"`
+	Items    map[string]int `json:"items,omitempty"` // Item ID to quantity
+}
+
+// ShopOffer represents items sold for currency
+type ShopOffer struct {
+	ID       string `json:"id"`
+	ItemID   string `json:"itemId"`
+	Quantity int    `json:"quantity"`
+	Price    int    `json:"price"` // In currency
+}
+
+// GetShopOffers returns every offer in the shop
+func GetShopOffers() []ShopOffer {
+	return []ShopOffer{
+		{ID: "ticket_generic_1", ItemID: ItemGenericTicket, Quantity: 1, Price: 160},
+		{ID: "ticket_generic_10", ItemID: ItemGenericTicket, Quantity: 10, Price: 1600},
+		{ID: "ticket_standard_10", ItemID: ItemStandardTicket, Quantity: 10, Price: 1500},
+	}
+}
+
+// FindShopOffer returns the shop offer with the given ID
+func FindShopOffer(id string) (ShopOffer, bool) {
+	for _, offer := range GetShopOffers() {
+		if offer.ID == id {
+			return offer, true
+		}
+	}
+	return ShopOffer{}, false
+}
+
+// ShopResponse represents the shop's offers and the items they sell
+type ShopResponse struct {
+	Offers []ShopOffer `json:"offers"`
+	Items  []Item      `json:"items"`
+}
+
+// ShopPurchaseRequest represents a purchase from the shop
+type ShopPurchaseRequest struct {
+	OfferID string `json:"offerId" binding:"required"`
+}
+
+// ShopPurchaseResponse represents the balances after a shop purchase
+type ShopPurchaseResponse struct {
+	Currency int            `json:"currency"`
+	Tickets  map[string]int `json:"tickets"`
+}
+
+// AdminItemRequest represents an admin grant of items
+type AdminItemRequest struct {
+	Username string `json:"username" binding:"required"`
+	ItemID   string `json:"itemId" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,gt=0"`
+	Reason   string `json:"reason"`
+}
+
+// ItemsResponse represents a user's item balances
+type ItemsResponse struct {
+	Items map[string]int `json:"items"`
+}
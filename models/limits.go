@@ -0,0 +1,53 @@
+package models
+
+// Age brackets chosen at registration
+const (
+	AgeBracketUnder13 = "under_13"
+	AgeBracket13To15  = "13_15"
+	AgeBracket16To17  = "16_17"
+	AgeBracketAdult   = "adult"
+)
+
+// ErrorCodeSpendingLimit identifies responses rejected by a spending limit
+const ErrorCodeSpendingLimit = "spending_limit_exceeded"
+
+// Spending tracks a user's currency purchases and pull spending in one calendar month
+type Spending struct {
+	Period    string `json:"period"` // Calendar month, e.g. "2025-01"
+	Purchased int    `json:"purchased"`
+	Spent     int    `json:"spent"`
+}
+
+// RegisterRequest represents request to register a user
+type RegisterRequest struct {
+	Username   string `json:"username" binding:"required"`
+	AgeBracket string `json:"ageBracket" binding:"required"`
+}
+
+// SetLimitRequest represents request to set a self-imposed monthly limit
+type SetLimitRequest struct {
+	SelfLimit int `json:"selfLimit" binding:"gte=0"` // 0 removes the limit
+}
+
+// LimitsResponse represents a user's spending limits and usage this month
+type LimitsResponse struct {
+	AgeBracket       string `json:"ageBracket"`
+	BracketLimit     int    `json:"bracketLimit"`               // 0 for unlimited
+	SelfLimit        int    `json:"selfLimit"`                  // 0 for none
+	PendingSelfLimit *int   `json:"pendingSelfLimit,omitempty"` // Self limit from resetsAt, when raised or removed this month
+	EffectiveLimit   int    `json:"effectiveLimit"`             // 0 for unlimited
+	Period           string `json:"period"`
+	Purchased        int    `json:"purchased"`
+	Spent            int    `json:"spent"`
+	ResetsAt         string `json:"resetsAt"`
+}
+
+// LimitErrorResponse represents a request rejected by a spending limit
+type LimitErrorResponse struct {
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	Limit     int    `json:"limit"`
+	Used      int    `json:"used"`
+	Requested int    `json:"requested"`
+	ResetsAt  string `json:"resetsAt"`
+}
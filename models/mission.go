@@ -0,0 +1,96 @@
+package models
+
+// Mission periods, after which progress resets
+const (
+	MissionDaily     = "daily"
+	MissionWeekly    = "weekly"
+	MissionPermanent = "permanent" // Achievements, never reset
+)
+
+// Objective kinds
+const (
+	ObjectivePulls        = "pulls"         // Pull Target times
+	ObjectiveObtainRarity = "obtain_rarity" // Pull Target characters of at least Rarity
+	ObjectiveOwnAll       = "own_all"       // Own every character of Rarity in the pool
+)
+
+// Error codes identifying mission claims that cannot be granted
+const (
+	ErrorCodeMissionIncomplete = "mission_incomplete"
+	ErrorCodeMissionClaimed    = "mission_claimed"
+)
+
+// Objective describes what completes a mission
+type Objective struct {
+	Kind   string `json:"kind"`
+	Target int    `json:"target,omitempty"` // Count to reach, for pulls and obtain_rarity
+	Rarity int    `json:"rarity,omitempty"` // For obtain_rarity and own_all
+}
+
+// Mission represents an objective with a reward, repeating each period
+type Mission struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Period    string    `json:"period"`
+	Objective Objective `json:"objective"`
+	Reward    Reward    `json:"reward"`
+}
+
+// MissionProgress tracks a user's progress on a mission in one period
+type MissionProgress struct {
+	Period   string `json:"period"` // Day, ISO week or empty for permanent missions, e.g. "2025-01-31" or "2025-W05"
+	Progress int    `json:"progress"`
+	Claimed  bool   `json:"claimed"`
+}
+
+// GetMissions returns every mission and achievement
+func GetMissions() []Mission {
+	return []Mission{
+		{ID: "daily_pull_1", Name: "Pull once", Period: MissionDaily,
+			Objective: Objective{Kind: ObjectivePulls, Target: 1}, Reward: Reward{Currency: 50}},
+		{ID: "daily_pull_10", Name: "Do 10 pulls", Period: MissionDaily,
+			Objective: Objective{Kind: ObjectivePulls, Target: 10}, Reward: Reward{Items: map[string]int{ItemGenericTicket: 1}}},
+		{ID: "weekly_pull_50", Name: "Do 50 pulls", Period: MissionWeekly,
+			Objective: Objective{Kind: ObjectivePulls, Target: 50}, Reward: Reward{Currency: 500}},
+		{ID: "weekly_sr_5", Name: "Obtain five 4★ or better", Period: MissionWeekly,
+			Objective: Objective{Kind: ObjectiveObtainRarity, Target: 5, Rarity: 4}, Reward: Reward{Currency: 300}},
+		{ID: "first_ssr", Name: "Obtain a 5★", Period: MissionPermanent,
+			Objective: Objective{Kind: ObjectiveObtainRarity, Target: 1, Rarity: 5}, Reward: Reward{Items: map[string]int{ItemGenericTicket: 2}}},
+		{ID: "pulls_100", Name: "Do 100 pulls", Period: MissionPermanent,
+			Objective: Objective{Kind: ObjectivePulls, Target: 100}, Reward: Reward{Currency: 1000}},
+		{ID: "all_sr", Name: "Own all 4★ characters", Period: MissionPermanent,
+			Objective: Objective{Kind: ObjectiveOwnAll, Rarity: 4}, Reward: Reward{Items: map[string]int{ItemGenericTicket: 5}}},
+		{ID: "all_ssr", Name: "Own all 5★ characters", Period: MissionPermanent,
+			Objective: Objective{Kind: ObjectiveOwnAll, Rarity: 5}, Reward: Reward{Items: map[string]int{ItemGenericTicket: 10}}},
+	}
+}
+
+// FindMission returns the mission with the given ID
+func FindMission(id string) (Mission, bool) {
+	for _, mission := range GetMissions() {
+		if mission.ID == id {
+			return mission, true
+		}
+	}
+	return Mission{}, false
+}
+
+// MissionStatus represents a user's progress on a mission
+type MissionStatus struct {
+	Mission   Mission `json:"mission"`
+	Progress  int     `json:"progress"`
+	Target    int     `json:"target"`
+	Completed bool    `json:"completed"`
+	Claimed   bool    `json:"claimed"`
+	ResetsAt  string  `json:"resetsAt,omitempty"` // RFC 3339, for daily and weekly missions
+}
+
+// MissionsResponse represents a user's missions
+type MissionsResponse struct {
+	Missions []MissionStatus `json:"missions"`
+}
+
+// MissionClaimRequest represents a claim of a completed mission's reward
+type MissionClaimRequest struct {
+	MissionID string `json:"missionId" binding:"required"`
+}
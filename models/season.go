@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Season pass reward tracks
+const (
+	TrackFree    = "free"
+	TrackPremium = "premium" // Unlocked per season with currency
+)
+
+// Error codes identifying season pass requests that cannot be granted
+const (
+	ErrorCodeSeasonInactive  = "season_inactive"
+	ErrorCodeTierLocked      = "tier_locked"
+	ErrorCodeTierClaimed     = "tier_claimed"
+	ErrorCodePremiumRequired = "premium_required"
+	ErrorCodePremiumOwned    = "premium_owned"
+)
+
+// Season represents a scheduled season pass
+type Season struct {
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	Start        time.Time    `json:"start"`
+	End          time.Time    `json:"end"` // Exclusive
+	XPPerTier    int          `json:"xpPerTier"`
+	PremiumPrice int          `json:"premiumPrice"` // Currency to unlock the premium track
+	Tiers        []SeasonTier `json:"tiers"`
+}
+
+// SeasonTier holds the rewards of one tier on each track
+type SeasonTier struct {
+	Free    Reward `json:"free"`
+	Premium Reward `json:"premium"`
+}
+
+// SeasonXP is the season XP earned by each action
+type SeasonXP struct {
+	Pull    int `json:"pull"`    // Per character pulled
+	Login   int `json:"login"`   // Per daily login reward claimed
+	Mission int `json:"mission"` // Per mission reward claimed
+}
+
+// SeasonProgress tracks a user's progress on a season pass
+type SeasonProgress struct {
+	SeasonID       string `json:"seasonId"`
+	XP             int    `json:"xp"`
+	Premium        bool   `json:"premium"`
+	ClaimedFree    []int  `json:"claimedFree"`    // Tiers claimed on the free track
+	ClaimedPremium []int  `json:"claimedPremium"` // Tiers claimed on the premium track
+}
+
+// SeasonTierStatus represents a user's standing on one tier
+type SeasonTierStatus struct {
+	Tier           int    `json:"tier"` // From 1
+	XPRequired     int    `json:"xpRequired"`
+	Unlocked       bool   `json:"unlocked"`
+	Free           Reward `json:"free"`
+	Premium        Reward `json:"premium"`
+	FreeClaimed    bool   `json:"freeClaimed"`
+	PremiumClaimed bool   `json:"premiumClaimed"`
+}
+
+// SeasonStatusResponse represents a user's progress on the active season
+// pass, and the next scheduled season
+type SeasonStatusResponse struct {
+	Active   bool               `json:"active"`
+	Season   *Season            `json:"season,omitempty"`   // Active season, omitted between seasons
+	Upcoming *Season            `json:"upcoming,omitempty"` // Next scheduled season, omitted when none
+	XP       int                `json:"xp"`
+	Tier     int                `json:"tier"` // Highest tier unlocked, 0 for none
+	Premium  bool               `json:"premium"`
+	Tiers    []SeasonTierStatus `json:"tiers"`
+}
+
+// SeasonClaimRequest represents a claim of a season pass tier reward
+type SeasonClaimRequest struct {
+	Tier  int    `json:"tier" binding:"required,gte=1"`
+	Track string `json:"track" binding:"required,oneof=free premium"`
+}
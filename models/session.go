@@ -0,0 +1,14 @@
+package models
+
+// ResumeRequest represents a request to resume a WebSocket session
+type ResumeRequest struct {
+	SessionID string `json:"sessionId"`
+	LastSeq   uint64 `json:"lastSeq"` // Sequence number of the last event received
+}
+
+// SessionResponse identifies the WebSocket session a client is attached to
+type SessionResponse struct {
+	SessionID string `json:"sessionId"`
+	Seq       uint64 `json:"seq"`     // Sequence number of the latest event
+	Resumed   bool   `json:"resumed"` // False when the client must rebuild its state
+}
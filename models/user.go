@@ -1,12 +1,41 @@
 package models
 
+import (
+	"maps"
+	"slices"
+)
+
 // User represents a player in the system
 type User struct {
-	ID        int         `json:"id"`
-	Username  string      `json:"username"`
-	Currency  int         `json:"currency"`  // Gacha currency
-	Inventory []Character `json:"inventory"` // Owned characters
-	PityCount int         `json:"pityCount"` // Pity counter
+	ID               int                        `json:"id"`
+	Username         string                     `json:"username"`
+	Currency         int                        `json:"currency"`                   // Gacha currency
+	Inventory        []Character                `json:"inventory"`                  // Owned characters
+	Items            map[string]int             `json:"items"`                      // Item ID to quantity held, apart from characters
+	PityCount        int                        `json:"pityCount"`                  // Pity counter
+	AgeBracket       string                     `json:"ageBracket"`                 // Set at registration
+	SelfLimit        int                        `json:"selfLimit"`                  // Self-imposed monthly limit, 0 for none
+	PendingSelfLimit *int                       `json:"pendingSelfLimit,omitempty"` // Raised or removed self limit, applied at the next monthly reset
+	Spending         Spending                   `json:"spending"`                   // Spending in the current month
+	Logins           LoginCalendar              `json:"logins"`                     // Daily login rewards claimed
+	Missions         map[string]MissionProgress `json:"missions"`                   // Mission ID to progress in its current period
+	Season           SeasonProgress             `json:"season"`                     // Progress on the active season pass
+}
+
+// Clone returns a copy of the user that shares no slices or maps with it
+func (u *User) Clone() User {
+	clone := *u
+	clone.Inventory = slices.Clone(u.Inventory)
+	clone.Items = maps.Clone(u.Items)
+	clone.Missions = maps.Clone(u.Missions)
+	if u.PendingSelfLimit != nil {
+		limit := *u.PendingSelfLimit
+		clone.PendingSelfLimit = &limit
+	}
+	clone.Logins.Days = slices.Clone(u.Logins.Days)
+	clone.Season.ClaimedFree = slices.Clone(u.Season.ClaimedFree)
+	clone.Season.ClaimedPremium = slices.Clone(u.Season.ClaimedPremium)
+	return clone
 }
 
 // HasCharacter checks if user owns a specific character
@@ -42,6 +71,42 @@ func (u *User) AddCurrency(amount int) {
 	u.Currency += amount
 }
 
+// ItemCount returns how many of an item the user holds
+func (u *User) ItemCount(itemID string) int {
+	return u.Items[itemID]
+}
+
+// AddItems adds a quantity of an item to the user
+func (u *User) AddItems(itemID string, quantity int) {
+	if u.Items == nil {
+		u.Items = make(map[string]int)
+	}
+	u.Items[itemID] += quantity
+}
+
+// RemoveItems removes a quantity of an item from the user
+func (u *User) RemoveItems(itemID string, quantity int) bool {
+	if u.Items[itemID] < quantity {
+		return false
+	}
+	u.Items[itemID] -= quantity
+	if u.Items[itemID] == 0 {
+		delete(u.Items, itemID)
+	}
+	return true
+}
+
+// PullTickets returns the user's pull ticket balances, including empty ones
+func (u *User) PullTickets() map[string]int {
+	tickets := make(map[string]int)
+	for _, item := range GetItems() {
+		if item.Kind == ItemKindPullTicket {
+			tickets[item.ID] = u.Items[item.ID]
+		}
+	}
+	return tickets
+}
+
 // IncrementPity increments the pity counter
 func (u *User) IncrementPity() {
 	u.PityCount++
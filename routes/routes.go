@@ -2,32 +2,151 @@ package routes
 
 import (
 	"gacha/handlers"
+	"gacha/metrics"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes configures all API routes
-func SetupRoutes(r *gin.Engine, gachaHandler *handlers.GachaHandler, userHandler *handlers.UserHandler, wsHandler *handlers.WebSocketHandler) {
+func SetupRoutes(r *gin.Engine, gachaHandler *handlers.GachaHandler, userHandler *handlers.UserHandler, fairnessHandler *handlers.FairnessHandler, wsHandler *handlers.WebSocketHandler, healthHandler *handlers.HealthHandler, adminHandler *handlers.AdminHandler, missionHandler *handlers.MissionHandler, seasonHandler *handlers.SeasonHandler, docsHandler *handlers.DocsHandler, metrics *metrics.Metrics) {
 	// WebSocket endpoint
 	r.GET("/ws", wsHandler.HandleWebSocket)
 
-	// HTTP API endpoints (kept for backward compatibility)
+	// Prometheus metrics
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// Health and build information
+	r.GET("/healthz", healthHandler.HandleHealthz)
+	r.GET("/readyz", healthHandler.HandleReadyz)
+	r.GET("/version", healthHandler.HandleVersion)
+
+	// API documentation, kept in sync with these routes by apidoc.Routes
+	r.GET("/openapi.json", docsHandler.HandleOpenAPI)
+	r.GET("/docs", docsHandler.HandleSwaggerUI)
+	r.GET("/asyncapi.json", docsHandler.HandleAsyncAPI)
+	r.GET("/schemas/ws/:type", docsHandler.HandleMessageSchema)
+
+	// HTTP API endpoints (kept for backward compatibility). Requests to the
+	// player routes claim the default user's daily login reward.
 	api := r.Group("/api")
 	{
 		// Gacha routes
-		gacha := api.Group("/gacha")
+		gacha := api.Group("/gacha", userHandler.TrackDailyLogin)
 		{
-			gacha.POST("/pull", gachaHandler.HandleSinglePull)
+			gacha.POST("/pull", gachaHandler.HandlePull)
 			gacha.POST("/pull-ten", gachaHandler.HandleTenPull)
 			gacha.GET("/pool", gachaHandler.HandleGetPool)
+			gacha.GET("/calculator", gachaHandler.HandleCalculator)
+			gacha.GET("/disclosure", gachaHandler.HandleDisclosure)
 		}
 
 		// User routes
-		user := api.Group("/user")
+		user := api.Group("/user", userHandler.TrackDailyLogin)
 		{
 			user.GET("/info", userHandler.HandleGetUserInfo)
 			user.GET("/inventory", userHandler.HandleGetInventory)
 			user.POST("/add-currency", userHandler.HandleAddCurrency)
+			user.POST("/register", userHandler.HandleRegister)
+			user.GET("/limits", userHandler.HandleGetLimits)
+			user.PUT("/limits", userHandler.HandleSetLimits)
+			user.GET("/daily", userHandler.HandleGetDaily)
+		}
+
+		// Shop routes
+		shop := api.Group("/shop", userHandler.TrackDailyLogin)
+		{
+			shop.GET("", userHandler.HandleGetShop)
+			shop.POST("/buy", userHandler.HandleBuy)
+		}
+
+		// Mission and achievement routes
+		missions := api.Group("/missions", userHandler.TrackDailyLogin)
+		{
+			missions.GET("", missionHandler.HandleListMissions)
+			missions.POST("/:id/claim", missionHandler.HandleClaimMission)
+		}
+
+		// Season pass routes
+		season := api.Group("/season", userHandler.TrackDailyLogin)
+		{
+			season.GET("", seasonHandler.HandleGetSeason)
+			season.POST("/claim", seasonHandler.HandleClaimTier)
+			season.POST("/premium", seasonHandler.HandleUnlockPremium)
+		}
+
+		// Provably fair routes
+		fairness := api.Group("/fairness")
+		{
+			// Anyone may verify a pull, so verifying claims no reward
+			fairness.POST("/verify", fairnessHandler.HandleVerify)
+
+			player := fairness.Group("", userHandler.TrackDailyLogin)
+			player.GET("", fairnessHandler.HandleGetCommitment)
+			player.POST("/client-seed", fairnessHandler.HandleSetClientSeed)
+			player.POST("/rotate", fairnessHandler.HandleRotate)
+		}
+
+		// Admin routes
+		admin := api.Group("/admin", adminHandler.RequireAdmin)
+		{
+			admin.POST("/grant", adminHandler.HandleGrant)
+			admin.POST("/refund", adminHandler.HandleRefund)
+			admin.POST("/items/grant", adminHandler.HandleGrantItems)
+			admin.PUT("/users/:username", adminHandler.HandleUpdateUser)
+			admin.GET("/audit", adminHandler.HandleQueryAudit)
+		}
+	}
+
+	// Versioned HTTP API with validated requests and a consistent error envelope
+	v2 := r.Group("/api/v2")
+	{
+		gacha := v2.Group("/gacha", userHandler.TrackDailyLogin)
+		{
+			gacha.POST("/pull", gachaHandler.HandlePullV2)
+			gacha.POST("/pull-ten", gachaHandler.HandleTenPullV2)
+			gacha.GET("/pool", gachaHandler.HandleGetPool)
+			gacha.GET("/calculator", gachaHandler.HandleCalculatorV2)
+			gacha.GET("/disclosure", gachaHandler.HandleDisclosureV2)
+		}
+
+		user := v2.Group("/user", userHandler.TrackDailyLogin)
+		{
+			user.GET("/info", userHandler.HandleGetUserInfo)
+			user.GET("/inventory", userHandler.HandleGetInventory)
+			user.POST("/add-currency", userHandler.HandleAddCurrencyV2)
+			user.POST("/register", userHandler.HandleRegisterV2)
+			user.GET("/limits", userHandler.HandleGetLimits)
+			user.PUT("/limits", userHandler.HandleSetLimitsV2)
+			user.GET("/daily", userHandler.HandleGetDaily)
+		}
+
+		shop := v2.Group("/shop", userHandler.TrackDailyLogin)
+		{
+			shop.GET("", userHandler.HandleGetShop)
+			shop.POST("/buy", userHandler.HandleBuyV2)
+		}
+
+		missions := v2.Group("/missions", userHandler.TrackDailyLogin)
+		{
+			missions.GET("", missionHandler.HandleListMissions)
+			missions.POST("/:id/claim", missionHandler.HandleClaimMissionV2)
+		}
+
+		season := v2.Group("/season", userHandler.TrackDailyLogin)
+		{
+			season.GET("", seasonHandler.HandleGetSeason)
+			season.POST("/claim", seasonHandler.HandleClaimTierV2)
+			season.POST("/premium", seasonHandler.HandleUnlockPremiumV2)
+		}
+
+		fairness := v2.Group("/fairness")
+		{
+			fairness.POST("/verify", fairnessHandler.HandleVerifyV2)
+
+			enabled := fairness.Group("", fairnessHandler.RequireFairness, userHandler.TrackDailyLogin)
+			enabled.GET("", fairnessHandler.HandleGetCommitment)
+			enabled.POST("/client-seed", fairnessHandler.HandleSetClientSeedV2)
+			enabled.POST("/rotate", fairnessHandler.HandleRotate)
 		}
 	}
 }
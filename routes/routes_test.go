@@ -0,0 +1,138 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"gacha/apidoc"
+	"gacha/config"
+	"gacha/handlers"
+	"gacha/internal/testenv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newRouter registers every route against in-memory services
+func newRouter(t *testing.T) (*gin.Engine, *testenv.Env) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	env := testenv.New(t, config.LoadConfig())
+	userLimiters := handlers.NewUserLimiters(env.Config.RateLimit.PerUser)
+	docsHandler, err := handlers.NewDocsHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	SetupRoutes(r,
+		handlers.NewGachaHandler(env.Gacha, env.Users, env.Economy, userLimiters, env.Logger, env.Config.Gacha),
+		handlers.NewUserHandler(env.Users, env.Limits, env.Economy, env.Daily, env.Logger),
+		handlers.NewFairnessHandler(env.Fairness, env.Users, env.Logger),
+		handlers.NewWebSocketHandler(env.Gacha, env.Users, env.Economy, env.Daily, env.Missions, env.Seasons, userLimiters, env.Metrics, env.Logger, env.Config.RateLimit),
+		handlers.NewHealthHandler(env.Config, env.Users, env.Audit),
+		handlers.NewAdminHandler(env.Users, env.Limits, env.Audit, env.Economy, env.Config.Admin, env.Logger),
+		handlers.NewMissionHandler(env.Users, env.Missions, env.Logger),
+		handlers.NewSeasonHandler(env.Users, env.Seasons, env.Logger),
+		docsHandler,
+		env.Metrics,
+	)
+	return r, env
+}
+
+func TestSpecMatchesRoutes(t *testing.T) {
+	var registered []string
+	r, _ := newRouter(t)
+	for _, route := range r.Routes() {
+		registered = append(registered, route.Method+" "+route.Path)
+	}
+	sort.Strings(registered)
+
+	documented := apidoc.Operations()
+	if strings.Join(registered, "\n") != strings.Join(documented, "\n") {
+		t.Errorf("apidoc.Routes and SetupRoutes differ\n%s", diff(documented, registered))
+	}
+}
+
+func diff(documented, registered []string) string {
+	seen := make(map[string]int)
+	for _, op := range documented {
+		seen[op]--
+	}
+	for _, op := range registered {
+		seen[op]++
+	}
+
+	var lines []string
+	for op, n := range seen {
+		switch {
+		case n < 0:
+			lines = append(lines, "documented but not registered: "+op)
+		case n > 0:
+			lines = append(lines, "registered but not documented: "+op)
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+func TestSpecIsConsistent(t *testing.T) {
+	doc := apidoc.OpenAPI()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every reference resolves to a component
+	for _, match := range regexp.MustCompile(`"\$ref":"#/components/schemas/(\w+)"`).FindAllStringSubmatch(string(data), -1) {
+		if doc.Components.Schemas[match[1]] == nil {
+			t.Errorf("unresolved reference to %s", match[1])
+		}
+	}
+
+	// Every path parameter is declared, and operation IDs are unique
+	ids := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			if ids[op.OperationID] {
+				t.Errorf("duplicate operation ID %s", op.OperationID)
+			}
+			ids[op.OperationID] = true
+
+			for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+				declared := false
+				for _, param := range op.Parameters {
+					declared = declared || (param.In == "path" && param.Name == match[1])
+				}
+				if !declared {
+					t.Errorf("%s %s: path parameter %s not declared", method, path, match[1])
+				}
+			}
+		}
+	}
+}
+
+func TestOnlyPlayerRoutesClaimDailyReward(t *testing.T) {
+	r, env := newRouter(t)
+	user := env.Users.GetDefaultUser(context.Background())
+
+	for _, path := range []string{"/api/fairness/verify", "/api/v2/fairness/verify"} {
+		body := `{"serverSeed": "seed", "pullType": "single"}`
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		if user.Logins.LastClaim != "" {
+			t.Fatalf("%s claimed the daily reward", path)
+		}
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/user/info", nil))
+	if user.Logins.LastClaim == "" {
+		t.Error("player route did not claim the daily reward")
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"gacha/audit"
+	"gacha/logging"
+	"gacha/models"
+)
+
+// AuditService keeps the append-only, hash-chained audit log of every action
+// affecting the economy, persisted as JSON Lines
+type AuditService struct {
+	file     *os.File // Nil when the log is kept in memory only
+	failed   error    // The first failed write, after which nothing is appended
+	entries  []models.AuditEntry
+	lastHash string
+	now      func() time.Time
+	logger   *slog.Logger
+	mu       sync.RWMutex
+}
+
+// NewAuditService opens the audit log at path, verifying the existing chain
+// before appending to it. An empty path keeps the log in memory.
+func NewAuditService(path string, logger *slog.Logger) (*AuditService, error) {
+	s := &AuditService{
+		lastHash: audit.GenesisHash,
+		now:      time.Now,
+		logger:   logger,
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	existing, err := os.Open(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("open audit log: %w", err)
+	default:
+		s.entries, err = audit.Read(existing)
+		existing.Close()
+		if err != nil {
+			return nil, err
+		}
+		if n := len(s.entries); n > 0 {
+			s.lastHash = s.entries[n-1].Hash
+		}
+	}
+
+	s.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return s, nil
+}
+
+// Close closes the audit log file
+func (s *AuditService) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+// Record appends an entry to the chain, filling in its sequence number, time
+// and hashes
+func (s *AuditService) Record(ctx context.Context, action, actor string, user *models.User, details interface{}) (models.AuditEntry, error) {
+	_, span := startSpan(ctx, "AuditService.Record")
+	defer span.End()
+
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return models.AuditEntry{}, fmt.Errorf("encode audit details: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A failed write may have left a partial line, so appending after it
+	// would make the file unreadable
+	if s.failed != nil {
+		return models.AuditEntry{}, s.failed
+	}
+
+	entry := models.AuditEntry{
+		Seq:      uint64(len(s.entries)) + 1,
+		Time:     s.now().UTC(),
+		Action:   action,
+		Actor:    actor,
+		UserID:   user.ID,
+		Username: user.Username,
+		Details:  raw,
+		PrevHash: s.lastHash,
+	}
+	if entry.Hash, err = audit.Hash(entry); err != nil {
+		return models.AuditEntry{}, err
+	}
+
+	if s.file != nil {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return models.AuditEntry{}, err
+		}
+		if _, err := s.file.Write(append(line, '\n')); err != nil {
+			s.failed = fmt.Errorf("write audit log: %w", err)
+			return models.AuditEntry{}, s.failed
+		}
+	}
+
+	s.entries = append(s.entries, entry)
+	s.lastHash = entry.Hash
+	return entry, nil
+}
+
+// RecordPull records a pull and the characters it produced
+func (s *AuditService) RecordPull(ctx context.Context, user *models.User, pullType string, cost int, tickets map[string]int, characters []models.Character) {
+	ids := make([]int, len(characters))
+	for i, char := range characters {
+		ids[i] = char.ID
+	}
+
+	s.record(ctx, models.AuditPull, models.ActorUserPrefix+user.Username, user, models.AuditPullDetails{
+		PullType:     pullType,
+		BannerID:     models.DefaultBanner().ID,
+		Cost:         cost,
+		Tickets:      tickets,
+		CharacterIDs: ids,
+		PityAfter:    user.PityCount,
+		Balance:      user.Currency,
+	})
+}
+
+// RecordCurrency records a purchase, grant or refund of currency
+func (s *AuditService) RecordCurrency(ctx context.Context, action, actor string, user *models.User, amount int, reason string) {
+	s.record(ctx, action, actor, user, models.AuditCurrencyDetails{
+		Amount:  amount,
+		Reason:  reason,
+		Balance: user.Currency,
+	})
+}
+
+// RecordItems records items granted to a user or bought from the shop
+func (s *AuditService) RecordItems(ctx context.Context, action, actor string, user *models.User, details models.AuditItemDetails) {
+	details.ItemBalance = user.ItemCount(details.ItemID)
+	details.Balance = user.Currency
+	s.record(ctx, action, actor, user, details)
+}
+
+// RecordChange records an admin change to one of a user's fields
+func (s *AuditService) RecordChange(ctx context.Context, actor string, user *models.User, field string, oldValue, newValue interface{}) {
+	s.record(ctx, models.AuditAdminChange, actor, user, models.AuditChangeDetails{
+		Field: field,
+		Old:   oldValue,
+		New:   newValue,
+	})
+}
+
+// Healthy returns the write failure that stopped the log, failing readiness
+// so that no more traffic reaches a server that cannot audit it
+func (s *AuditService) Healthy() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.failed
+}
+
+// record appends an entry, logging rather than returning failures since the
+// audited action has already taken place; Healthy reports them instead
+func (s *AuditService) record(ctx context.Context, action, actor string, user *models.User, details interface{}) {
+	if _, err := s.Record(ctx, action, actor, user, details); err != nil {
+		s.logger.ErrorContext(ctx, "failed to record audit entry",
+			logging.UserIDKey, user.ID, "action", action, "actor", actor, "error", err)
+	}
+}
+
+// Query returns the entries matching filter, oldest first, keeping the most
+// recent filter.Limit when set
+func (s *AuditService) Query(filter models.AuditFilter) []models.AuditEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := []models.AuditEntry{}
+	for _, entry := range s.entries {
+		if filter.Username != "" && entry.Username != filter.Username {
+			continue
+		}
+		if filter.Actor != "" && entry.Actor != filter.Actor {
+			continue
+		}
+		if filter.Action != "" && entry.Action != filter.Action {
+			continue
+		}
+		if !filter.From.IsZero() && entry.Time.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !entry.Time.Before(filter.To) {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[len(matched)-filter.Limit:]
+	}
+	return matched
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gacha/audit"
+	"gacha/logging"
+	"gacha/models"
+)
+
+func recordSample(t *testing.T, s *AuditService) {
+	t.Helper()
+	ctx := context.Background()
+	user := &models.User{ID: 1, Username: "alice", Currency: 840}
+
+	s.RecordPull(ctx, user, models.PullTypeSingle, 160, nil, []models.Character{{ID: 3, Rarity: 5}})
+	s.RecordCurrency(ctx, models.AuditPurchase, models.ActorUserPrefix+"alice", user, 1000, "")
+	s.RecordCurrency(ctx, models.AuditGrant, models.ActorAdminPrefix+"ops", user, 500, "outage compensation")
+}
+
+func TestAuditChainSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	first, err := NewAuditService(path, logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordSample(t, first)
+	first.Close()
+
+	second, err := NewAuditService(path, logging.Discard())
+	if err != nil {
+		t.Fatalf("reopen intact log: %v", err)
+	}
+	recordSample(t, second)
+	second.Close()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	entries, err := audit.Read(file)
+	if err != nil {
+		t.Fatalf("verify log: %v", err)
+	}
+	if len(entries) != 6 || entries[5].Seq != 6 {
+		t.Errorf("read %d entries, want 6 in sequence", len(entries))
+	}
+}
+
+func TestAuditWriteFailureStopsLog(t *testing.T) {
+	s, err := NewAuditService(filepath.Join(t.TempDir(), "audit.jsonl"), logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordSample(t, s)
+	if err := s.Healthy(); err != nil {
+		t.Fatalf("healthy log reports %v", err)
+	}
+
+	// Writes to a closed file fail
+	s.file.Close()
+	user := &models.User{ID: 1, Username: "alice"}
+	if _, err := s.Record(context.Background(), models.AuditPurchase, models.ActorUserPrefix+"alice", user, nil); err == nil {
+		t.Fatal("record succeeded on a closed file")
+	}
+	if err := s.Healthy(); err == nil {
+		t.Error("failed log reports healthy")
+	}
+	if n := len(s.Query(models.AuditFilter{})); n != 3 {
+		t.Errorf("log holds %d entries after a failed write, want 3", n)
+	}
+}
+
+func TestAuditChainDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	s, err := NewAuditService(path, logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordSample(t, s)
+	s.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tamper := range map[string]func([]string) []string{
+		"edited amount": func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], `"amount":1000`, `"amount":100000`, 1)
+			return lines
+		},
+		"removed entry": func(lines []string) []string {
+			return append(lines[:1], lines[2:]...)
+		},
+		"reordered entries": func(lines []string) []string {
+			lines[1], lines[2] = lines[2], lines[1]
+			return lines
+		},
+	} {
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		tampered := strings.Join(tamper(lines), "\n")
+
+		var chainErr *audit.ChainError
+		if _, err := audit.Read(strings.NewReader(tampered)); !errors.As(err, &chainErr) {
+			t.Errorf("%s: verification returned %v, want a chain error", name, err)
+			continue
+		}
+		if chainErr.Line != 2 {
+			t.Errorf("%s: break reported at line %d, want 2", name, chainErr.Line)
+		}
+
+		if err := os.WriteFile(path, []byte(tampered), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewAuditService(path, logging.Discard()); err == nil {
+			t.Errorf("%s: service opened a tampered log", name)
+		}
+	}
+}
+
+func TestAuditQueryFilters(t *testing.T) {
+	s, err := NewAuditService("", logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	recordSample(t, s)
+	now = now.Add(time.Hour)
+	recordSample(t, s)
+
+	cases := []struct {
+		name   string
+		filter models.AuditFilter
+		want   int
+	}{
+		{"all", models.AuditFilter{}, 6},
+		{"actor", models.AuditFilter{Actor: models.ActorAdminPrefix + "ops"}, 2},
+		{"action", models.AuditFilter{Action: models.AuditPull}, 2},
+		{"other user", models.AuditFilter{Username: "bob"}, 0},
+		{"from", models.AuditFilter{From: now}, 3},
+		{"to", models.AuditFilter{To: now}, 3},
+		{"limit", models.AuditFilter{Limit: 4}, 4},
+	}
+	for _, tc := range cases {
+		if got := len(s.Query(tc.filter)); got != tc.want {
+			t.Errorf("%s: %d entries, want %d", tc.name, got, tc.want)
+		}
+	}
+}
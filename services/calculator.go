@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+
+	"gacha/models"
+)
+
+const (
+	MaxCalculatorPulls = 100000 // Upper bound on the pulls the calculator walks through and charts
+	survivalEpsilon    = 1e-12  // Remaining probability treated as negligible
+)
+
+// calculatorMilestones are the probabilities reported as milestones
+var calculatorMilestones = []float64{0.5, 0.9, 0.99}
+
+// ErrUnobtainable is returned when a target cannot be obtained from the pool
+var ErrUnobtainable = errors.New("target cannot be obtained from this pool")
+
+// CalculateOdds computes the chance of obtaining target within the given
+// number of pulls, starting from pity, and the expected cost of obtaining it.
+// With guaranteed set, the next SSR is assumed to be the target.
+func (s *GachaService) CalculateOdds(target models.Character, pity int, guaranteed bool, pulls, pullCost int) (models.CalculatorResponse, error) {
+	dist, certain := s.pullsToTarget(target, pity, guaranteed)
+	if dist == nil {
+		return models.CalculatorResponse{}, ErrUnobtainable
+	}
+
+	response := models.CalculatorResponse{
+		Target:     target,
+		Pity:       pity,
+		Guaranteed: guaranteed && target.Rarity == 5,
+		Pulls:      pulls,
+		Cost:       pulls * pullCost,
+	}
+
+	cumulative := 0.0
+	milestone := 0
+	for i, p := range dist {
+		n := i + 1
+		cumulative += p
+		if n <= pulls {
+			response.Probability = cumulative
+		}
+		response.ExpectedPulls += float64(n) * p
+
+		for milestone < len(calculatorMilestones) && cumulative >= calculatorMilestones[milestone] {
+			response.Milestones = append(response.Milestones, models.CalculatorPoint{
+				Probability: calculatorMilestones[milestone],
+				Pulls:       n,
+				Cost:        n * pullCost,
+			})
+			milestone++
+		}
+	}
+	response.ExpectedCost = response.ExpectedPulls * float64(pullCost)
+	if certain {
+		response.MaxPulls = len(dist)
+	}
+
+	return response, nil
+}
+
+// pullsToTarget walks the pity state machine and returns the probability that
+// the target first appears on each pull, and whether it is certain by the last.
+// It returns nil if the target can never be obtained.
+func (s *GachaService) pullsToTarget(target models.Character, pity int, guaranteed bool) ([]float64, bool) {
+	totalRate, ssrRate, ssrCount := 0.0, 0.0, 0
+	for _, char := range s.engine.Pool() {
+		totalRate += char.Rate
+		if char.Rarity == 5 {
+			ssrRate += char.Rate
+			ssrCount++
+		}
+	}
+
+	isSSR := target.Rarity == 5
+	pTarget := target.Rate / totalRate // Target on a normal pull
+	pSSR := ssrRate / totalRate        // Any SSR on a normal pull
+	pOther := pSSR                     // An SSR other than the target, which resets pity
+	pForced := 0.0                     // Target when pity forces a random SSR
+	if isSSR {
+		pOther -= pTarget
+		pForced = 1 / float64(ssrCount)
+	}
+	guaranteed = guaranteed && isSSR
+	if pTarget == 0 && pForced == 0 && !guaranteed {
+		return nil, false
+	}
+
+	threshold := s.engine.PityThreshold()
+	if pity < 0 {
+		pity = 0
+	}
+	if pity > threshold-1 {
+		pity = threshold - 1
+	}
+
+	// state[g][p] is the probability of not yet having the target with pity
+	// count p, where g marks that the next SSR is guaranteed to be the target
+	state := [2][]float64{make([]float64, threshold), make([]float64, threshold)}
+	g := 0
+	if guaranteed {
+		g = 1
+	}
+	state[g][pity] = 1
+
+	var dist []float64
+	for len(dist) < MaxCalculatorPulls {
+		next := [2][]float64{make([]float64, threshold), make([]float64, threshold)}
+		hit, remaining := 0.0, 0.0
+
+		for g := range state {
+			for p, mass := range state[g] {
+				if mass == 0 {
+					continue
+				}
+
+				if p+1 >= threshold {
+					// Pity forces an SSR
+					if g == 1 {
+						hit += mass
+					} else {
+						hit += mass * pForced
+						next[0][0] += mass * (1 - pForced)
+					}
+					continue
+				}
+
+				if g == 1 {
+					hit += mass * pSSR
+					next[1][p+1] += mass * (1 - pSSR)
+					continue
+				}
+
+				hit += mass * pTarget
+				next[0][0] += mass * pOther
+				next[0][p+1] += mass * (1 - pTarget - pOther)
+			}
+		}
+
+		for g := range next {
+			for _, mass := range next[g] {
+				remaining += mass
+			}
+		}
+
+		dist = append(dist, hit)
+		state = next
+		if remaining == 0 {
+			return dist, true
+		}
+		if remaining < survivalEpsilon {
+			break
+		}
+	}
+
+	return dist, false
+}
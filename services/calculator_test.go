@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"gacha/models"
+)
+
+func findCharacter(t *testing.T, service *GachaService, id int) models.Character {
+	t.Helper()
+	for _, char := range service.GetCharacterPool() {
+		if char.ID == id {
+			return char
+		}
+	}
+	t.Fatalf("character %d not in pool", id)
+	return models.Character{}
+}
+
+func TestCalculateOddsGuaranteedIsCertainAtPity(t *testing.T) {
+	service := newSeededService(1)
+	target := findCharacter(t, service, 3)
+
+	odds, err := service.CalculateOdds(target, 80, true, 10, 160)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := service.PityThreshold() - 80; odds.MaxPulls != want {
+		t.Errorf("max pulls = %d, want %d", odds.MaxPulls, want)
+	}
+	if math.Abs(odds.Probability-1) > 1e-9 {
+		t.Errorf("probability within max pulls = %f, want 1", odds.Probability)
+	}
+}
+
+func TestCalculateOddsMatchesSimulation(t *testing.T) {
+	const users = 20000
+	service := newSeededService(41)
+	target := findCharacter(t, service, 3)
+
+	odds, err := service.CalculateOdds(target, 0, false, 90, 160)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	within, totalPulls := 0, 0
+	for i := 0; i < users; i++ {
+		user := &models.User{}
+		pulls := 1
+		for service.PerformSinglePull(context.Background(), user).ID != target.ID {
+			pulls++
+		}
+		totalPulls += pulls
+		if pulls <= odds.Pulls {
+			within++
+		}
+	}
+
+	assertFits(t, "probability within 90 pulls", []int{within, users - within},
+		[]float64{odds.Probability, 1 - odds.Probability})
+
+	mean := float64(totalPulls) / users
+	if math.Abs(mean-odds.ExpectedPulls)/odds.ExpectedPulls > 0.03 {
+		t.Errorf("simulated mean %.2f pulls, calculator expects %.2f", mean, odds.ExpectedPulls)
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"gacha/config"
+	"gacha/logging"
+	"gacha/models"
+)
+
+// SourceDailyReward identifies daily login rewards in metrics
+const SourceDailyReward = "daily_reward"
+
+// DailyService grants a reward on each user's first activity of a calendar
+// day, tracking login streaks and a calendar of the current month
+type DailyService struct {
+	economyService *EconomyService
+	events         *EventBus
+	rewards        []models.Reward
+	location       *time.Location
+	now            func() time.Time
+	logger         *slog.Logger
+	mu             sync.Mutex // Guards the login calendars
+}
+
+// NewDailyService creates a new daily reward service
+func NewDailyService(cfg config.DailyConfig, events *EventBus, economyService *EconomyService, logger *slog.Logger) (*DailyService, error) {
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("load daily timezone: %w", err)
+	}
+
+	return &DailyService{
+		economyService: economyService,
+		events:         events,
+		rewards:        cfg.Rewards,
+		location:       location,
+		now:            time.Now,
+		logger:         logger,
+	}, nil
+}
+
+// SetClock replaces the time source of claims, letting tests step from one
+// day to the next
+func (s *DailyService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Claim grants today's reward to a user who has not claimed it yet. It
+// returns nil when the reward was already claimed or rewards are disabled,
+// and publishes EventLogin otherwise.
+func (s *DailyService) Claim(ctx context.Context, user *models.User) (*models.DailyReward, error) {
+	ctx, span := startSpan(ctx, "DailyService.Claim")
+	defer span.End()
+
+	if len(s.rewards) == 0 {
+		return nil, nil
+	}
+
+	claimed, err := s.claim(ctx, user)
+	if claimed != nil {
+		s.events.Publish(ctx, Event{Kind: EventLogin, User: user})
+	}
+	return claimed, err
+}
+
+// claim grants today's reward under mu, which listeners may not be called with
+func (s *DailyService) claim(ctx context.Context, user *models.User) (*models.DailyReward, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	today := s.now().In(s.location)
+	date := today.Format(time.DateOnly)
+	var calendar models.LoginCalendar
+	s.economyService.Update(user, func() { calendar = user.Logins })
+	if calendar.LastClaim == date {
+		return nil, nil
+	}
+
+	streak := 1
+	if calendar.LastClaim == today.AddDate(0, 0, -1).Format(time.DateOnly) {
+		streak = calendar.Streak + 1
+	}
+	claimed := &models.DailyReward{
+		Date:   date,
+		Streak: streak,
+		Reward: s.rewardFor(streak),
+	}
+
+	reason := fmt.Sprintf("daily login %s, streak day %d", date, streak)
+	if err := s.economyService.Grant(ctx, user, models.ActorUserPrefix+user.Username, SourceDailyReward, claimed.Reward, reason); err != nil {
+		return nil, err
+	}
+
+	calendar.LastClaim = date
+	calendar.Streak = streak
+	calendar.LongestStreak = max(calendar.LongestStreak, streak)
+	if month := today.Format("2006-01"); calendar.Month != month {
+		calendar.Month = month
+		calendar.Days = nil
+	}
+	calendar.Days = append(slices.Clip(calendar.Days), today.Day())
+	calendar.Total++
+	s.economyService.Update(user, func() { user.Logins = calendar })
+
+	logging.FromContext(ctx, s.logger).InfoContext(ctx, "daily reward claimed", "date", date, "streak", streak)
+	return claimed, nil
+}
+
+// Status returns a user's login calendar for the current month and the
+// reward of their next claim
+func (s *DailyService) Status(user *models.User) models.DailyStatusResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	today := s.now().In(s.location)
+	date := today.Format(time.DateOnly)
+	var calendar models.LoginCalendar
+	s.economyService.Update(user, func() { calendar = user.Logins })
+
+	status := models.DailyStatusResponse{
+		Today:         date,
+		Claimed:       calendar.LastClaim == date,
+		LongestStreak: calendar.LongestStreak,
+		Month:         today.Format("2006-01"),
+		Days:          []int{},
+		Rewards:       s.rewards,
+		ResetsAt:      s.nextReset(today).Format(time.RFC3339),
+	}
+	if calendar.Month == status.Month {
+		status.Days = append(status.Days, calendar.Days...)
+	}
+
+	// The streak survives until a day passes without a claim
+	next := 1
+	switch calendar.LastClaim {
+	case date, today.AddDate(0, 0, -1).Format(time.DateOnly):
+		status.Streak = calendar.Streak
+		next = calendar.Streak + 1
+	}
+	if len(s.rewards) > 0 {
+		reward := s.rewardFor(next)
+		status.NextReward = &reward
+	}
+	return status
+}
+
+// NextReset returns when the next day's reward becomes claimable
+func (s *DailyService) NextReset() time.Time {
+	return s.nextReset(s.now().In(s.location))
+}
+
+// nextReset returns the start of the day after today
+func (s *DailyService) nextReset(today time.Time) time.Time {
+	return time.Date(today.Year(), today.Month(), today.Day()+1, 0, 0, 0, 0, s.location)
+}
+
+// rewardFor returns the reward for a streak day, cycling through the rewards
+func (s *DailyService) rewardFor(streak int) models.Reward {
+	return s.rewards[(streak-1)%len(s.rewards)]
+}
@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gacha/config"
+	"gacha/internal/testenv"
+	"gacha/models"
+)
+
+// dailyEnv returns services whose daily rewards reset in timezone, cycling
+// through three days of rewards
+func dailyEnv(t *testing.T, timezone string) *testenv.Env {
+	t.Helper()
+	cfg := config.LoadConfig()
+	cfg.Daily.Timezone = timezone
+	cfg.Daily.Rewards = []models.Reward{
+		{Currency: 100},
+		{Currency: 200},
+		{Items: map[string]int{models.ItemGenericTicket: 1}},
+	}
+	return testenv.New(t, cfg)
+}
+
+func TestClaimOncePerDayAndCyclesRewards(t *testing.T) {
+	env := dailyEnv(t, "UTC")
+	env.Clock.Set(time.Date(2026, 3, 30, 12, 0, 0, 0, time.UTC))
+	service := env.Daily
+	user := &models.User{Username: "daily"}
+
+	for day := 1; day <= 4; day++ {
+		reward, err := service.Claim(context.Background(), user)
+		if err != nil || reward == nil {
+			t.Fatalf("day %d: claim returned %v, %v", day, reward, err)
+		}
+		if reward.Streak != day {
+			t.Errorf("day %d: streak %d", day, reward.Streak)
+		}
+		if again, _ := service.Claim(context.Background(), user); again != nil {
+			t.Errorf("day %d: claimed twice", day)
+		}
+		env.Clock.Set(env.Clock.Now().AddDate(0, 0, 1))
+	}
+
+	// Days 1 to 3 of the cycle, then day 1 again
+	if user.Currency != 100+200+100 || user.ItemCount(models.ItemGenericTicket) != 1 {
+		t.Errorf("balance %d with %d tickets after four days", user.Currency, user.ItemCount(models.ItemGenericTicket))
+	}
+
+	// The calendar restarted with April
+	status := service.Status(user)
+	if status.Month != "2026-04" || len(status.Days) != 2 || user.Logins.Total != 4 {
+		t.Errorf("calendar %s days %v total %d", status.Month, status.Days, user.Logins.Total)
+	}
+	if status.Claimed || status.Streak != 4 || status.NextReward.Currency != 200 {
+		t.Errorf("status %+v before the fifth claim", status)
+	}
+}
+
+func TestClaimResetsStreakAfterMissedDayInTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("timezone database unavailable")
+	}
+
+	// 23:30 and 00:30 Tokyo time fall on consecutive Tokyo days but the same UTC day
+	env := dailyEnv(t, "Asia/Tokyo")
+	env.Clock.Set(time.Date(2026, 5, 10, 23, 30, 0, 0, tokyo))
+	service := env.Daily
+	user := &models.User{Username: "tokyo"}
+
+	service.Claim(context.Background(), user)
+	env.Clock.Advance(time.Hour)
+	if reward, _ := service.Claim(context.Background(), user); reward == nil || reward.Streak != 2 {
+		t.Fatalf("claim after Tokyo midnight returned %+v", reward)
+	}
+
+	env.Clock.Set(env.Clock.Now().AddDate(0, 0, 2))
+	if status := service.Status(user); status.Streak != 0 {
+		t.Errorf("streak %d shown after a missed day", status.Streak)
+	}
+	if reward, _ := service.Claim(context.Background(), user); reward == nil || reward.Streak != 1 {
+		t.Errorf("claim after a missed day returned %+v", reward)
+	}
+	if user.Logins.LongestStreak != 2 {
+		t.Errorf("longest streak %d", user.Logins.LongestStreak)
+	}
+}
+
+func TestLoginCalendarIsWrittenUnderTheUserLock(t *testing.T) {
+	env := dailyEnv(t, "UTC")
+	env.Clock.Set(time.Date(2026, 3, 30, 12, 0, 0, 0, time.UTC))
+	service := env.Daily
+	user := &models.User{Username: "daily"}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := service.Claim(context.Background(), user); err != nil {
+			t.Error(err)
+		}
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			env.Economy.Update(user, func() { _ = user.Logins.LastClaim })
+		}
+	}
+
+	if snapshot := env.Economy.Snapshot(user); snapshot.Logins.LastClaim != "2026-03-30" || snapshot.Logins.Total != 1 {
+		t.Errorf("calendar %+v after one claim", snapshot.Logins)
+	}
+}
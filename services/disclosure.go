@@ -0,0 +1,206 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"gacha/models"
+)
+
+const (
+	stationaryIterations = 10000 // Cap on power iterations for the long-run pity distribution
+	stationaryEpsilon    = 1e-15
+)
+
+// Disclosure computes the exact per-character and per-rarity probabilities of
+// the pool, both from weights alone and consolidated with pity and the ten
+// pull SR guarantee
+func (s *GachaService) Disclosure(bannerID string, now time.Time) models.Disclosure {
+	totalRate := 0.0
+	for _, char := range s.engine.Pool() {
+		totalRate += char.Rate
+	}
+
+	single := s.consolidatedRates(1)
+	ten := s.consolidatedRates(10)
+
+	disclosure := models.Disclosure{
+		BannerID:      bannerID,
+		Version:       s.disclosureVersion(),
+		GeneratedAt:   now.UTC().Format(time.RFC3339),
+		PityThreshold: s.engine.PityThreshold(),
+		Rules: []string{
+			fmt.Sprintf("An SSR is guaranteed on pull %d since the last SSR; the guaranteed SSR is chosen uniformly among SSR characters.", s.engine.PityThreshold()),
+			"Every ten pull contains at least one SR or better; if the first nine pulls and the tenth roll are all R, the tenth becomes an SR chosen uniformly among SR characters.",
+			"Consolidated rates are long-run averages per pull with the pity counter in its steady state.",
+		},
+	}
+
+	byRarity := make(map[int]*models.RarityProbability)
+	for i, char := range s.engine.Pool() {
+		rates := models.ProbabilityRates{
+			BaseRate:       char.Rate / totalRate,
+			SinglePullRate: single[i],
+			TenPullRate:    ten[i],
+		}
+		disclosure.Characters = append(disclosure.Characters, models.CharacterProbability{
+			ID:               char.ID,
+			Name:             char.Name,
+			Rarity:           char.Rarity,
+			ProbabilityRates: rates,
+		})
+
+		rarity, ok := byRarity[char.Rarity]
+		if !ok {
+			rarity = &models.RarityProbability{Rarity: char.Rarity}
+			byRarity[char.Rarity] = rarity
+		}
+		rarity.BaseRate += rates.BaseRate
+		rarity.SinglePullRate += rates.SinglePullRate
+		rarity.TenPullRate += rates.TenPullRate
+	}
+
+	for _, rarity := range byRarity {
+		disclosure.Rarities = append(disclosure.Rarities, *rarity)
+	}
+	sort.Slice(disclosure.Rarities, func(i, j int) bool {
+		return disclosure.Rarities[i].Rarity > disclosure.Rarities[j].Rarity
+	})
+
+	return disclosure
+}
+
+// disclosureVersion hashes everything that determines the disclosed probabilities
+func (s *GachaService) disclosureVersion() string {
+	data, _ := json.Marshal(struct {
+		Pool          []models.Character
+		PityThreshold int
+	}{s.engine.Pool(), s.engine.PityThreshold()})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// consolidatedRates returns the long-run probability per pull of each pool
+// character when pulling in batches of the given size, by finding the steady
+// state of the pity counter between batches
+func (s *GachaService) consolidatedRates(batchSize int) []float64 {
+	threshold := s.engine.PityThreshold()
+	transitions := make([][]float64, threshold)
+	counts := make([][]float64, threshold)
+	for pity := 0; pity < threshold; pity++ {
+		transitions[pity], counts[pity] = s.batchOutcomes(pity, batchSize)
+	}
+
+	// Power iteration from a fresh account
+	stationary := make([]float64, threshold)
+	stationary[0] = 1
+	for iter := 0; iter < stationaryIterations; iter++ {
+		next := make([]float64, threshold)
+		for from, mass := range stationary {
+			for to, p := range transitions[from] {
+				next[to] += mass * p
+			}
+		}
+
+		delta := 0.0
+		for i := range next {
+			if d := next[i] - stationary[i]; d > delta {
+				delta = d
+			} else if -d > delta {
+				delta = -d
+			}
+		}
+		stationary = next
+		if delta < stationaryEpsilon {
+			break
+		}
+	}
+
+	rates := make([]float64, len(s.engine.Pool()))
+	for pity, mass := range stationary {
+		for i, count := range counts[pity] {
+			rates[i] += mass * count / float64(batchSize)
+		}
+	}
+	return rates
+}
+
+// batchOutcomes computes, for a batch of pulls starting at the given pity,
+// the distribution of the pity count afterwards and the expected number of
+// each pool character obtained, mirroring PerformSinglePull and PerformTenPull
+func (s *GachaService) batchOutcomes(startPity, batchSize int) ([]float64, []float64) {
+	threshold := s.engine.PityThreshold()
+	totalRate := 0.0
+	var ssr, sr []int
+	for i, char := range s.engine.Pool() {
+		totalRate += char.Rate
+		switch char.Rarity {
+		case 5:
+			ssr = append(ssr, i)
+		case 4:
+			sr = append(sr, i)
+		}
+	}
+
+	counts := make([]float64, len(s.engine.Pool()))
+
+	// state[hasSR][pity] is the probability of being at that point in the batch
+	state := [2][]float64{make([]float64, threshold), make([]float64, threshold)}
+	state[0][startPity] = 1
+
+	for i := 0; i < batchSize; i++ {
+		guaranteeSR := batchSize == 10 && i == 9
+		next := [2][]float64{make([]float64, threshold), make([]float64, threshold)}
+
+		for hasSR := range state {
+			for pity, mass := range state[hasSR] {
+				if mass == 0 {
+					continue
+				}
+
+				if pity+1 >= threshold {
+					// Pity forces an SSR chosen uniformly
+					for _, idx := range ssr {
+						counts[idx] += mass / float64(len(ssr))
+					}
+					next[1][0] += mass
+					continue
+				}
+
+				for idx, char := range s.engine.Pool() {
+					p := mass * char.Rate / totalRate
+					switch {
+					case char.Rarity == 5:
+						counts[idx] += p
+						next[1][0] += p
+					case char.Rarity == 4:
+						counts[idx] += p
+						next[1][pity+1] += p
+					case guaranteeSR && hasSR == 0 && len(sr) > 0:
+						// Replaced by an SR chosen uniformly; pity still counts the roll
+						for _, srIdx := range sr {
+							counts[srIdx] += p / float64(len(sr))
+						}
+						next[1][pity+1] += p
+					default:
+						counts[idx] += p
+						next[hasSR][pity+1] += p
+					}
+				}
+			}
+		}
+		state = next
+	}
+
+	transitions := make([]float64, threshold)
+	for hasSR := range state {
+		for pity, mass := range state[hasSR] {
+			transitions[pity] += mass
+		}
+	}
+	return transitions, counts
+}
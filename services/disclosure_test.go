@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"gacha/config"
+	"gacha/models"
+)
+
+func TestDisclosureRatesSumToOne(t *testing.T) {
+	disclosure := newSeededService(1).Disclosure("standard", time.Now())
+
+	var base, single, ten float64
+	for _, char := range disclosure.Characters {
+		base += char.BaseRate
+		single += char.SinglePullRate
+		ten += char.TenPullRate
+	}
+	for name, sum := range map[string]float64{"base": base, "single": single, "ten": ten} {
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("%s rates sum to %.12f", name, sum)
+		}
+	}
+}
+
+func TestDisclosureSinglePullSSRMatchesClosedForm(t *testing.T) {
+	cfg := config.LoadConfig()
+	service := newSeededService(1)
+	disclosure := service.Disclosure("standard", time.Now())
+
+	want := consolidatedSSRRate(cfg.Gacha.SSRRate, service.PityThreshold())
+	for _, rarity := range disclosure.Rarities {
+		if rarity.Rarity == 5 && math.Abs(rarity.SinglePullRate-want) > 1e-9 {
+			t.Errorf("consolidated SSR rate %.9f, closed form gives %.9f", rarity.SinglePullRate, want)
+		}
+	}
+}
+
+func TestDisclosureTenPullMatchesEngine(t *testing.T) {
+	const batches = 100000
+	service := newSeededService(42)
+	disclosure := service.Disclosure("standard", time.Now())
+
+	index := make(map[int]int)
+	expected := make([]float64, len(disclosure.Characters))
+	for i, char := range disclosure.Characters {
+		index[char.ID] = i
+		expected[i] = char.TenPullRate
+	}
+
+	observed := make([]int, len(expected))
+	user := &models.User{}
+	for i := 0; i < batches; i++ {
+		for _, char := range service.PerformTenPull(context.Background(), user) {
+			observed[index[char.ID]]++
+		}
+	}
+
+	assertFits(t, "ten pull per character", observed, expected)
+}
@@ -0,0 +1,520 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gacha/config"
+	"gacha/logging"
+	"gacha/metrics"
+	"gacha/models"
+)
+
+// Errors returned for operations that cannot be performed
+var (
+	ErrInsufficientCurrency = errors.New("insufficient currency")
+	ErrInsufficientTickets  = errors.New("insufficient pull tickets")
+	ErrInvalidPull          = errors.New("invalid pull request")
+	ErrInvalidAmount        = errors.New("amount must be positive")
+	ErrUnknownItem          = errors.New("unknown item")
+	ErrUnknownOffer         = errors.New("unknown shop offer")
+)
+
+// Sources of granted items and reasons items or currency were spent, as
+// recorded in metrics
+const (
+	SourceAdmin  = "admin"
+	SourceShop   = "shop"
+	SourceRefund = "refund"
+)
+
+// EconomyService performs the operations that move currency and items:
+// pulls, purchases, grants and the shop, enforcing spending limits and recording metrics, logs and audit
+// entries, so that every API version and the WebSocket share one path. It
+// logs through the logger carried by the context, if any.
+type EconomyService struct {
+	gachaService    *GachaService
+	fairnessService *FairnessService
+	limitService    *LimitService
+	auditService    *AuditService
+	events          *EventBus
+	metrics         *metrics.Metrics
+	gachaConfig     config.GachaConfig
+	logger          *slog.Logger
+	userLocks       sync.Map // Username to *sync.Mutex, serializing each user's transactions
+}
+
+// NewEconomyService creates a new economy service
+func NewEconomyService(gachaService *GachaService, fairnessService *FairnessService, limitService *LimitService, auditService *AuditService, events *EventBus, metrics *metrics.Metrics, gachaConfig config.GachaConfig, logger *slog.Logger) *EconomyService {
+	return &EconomyService{
+		gachaService:    gachaService,
+		fairnessService: fairnessService,
+		limitService:    limitService,
+		auditService:    auditService,
+		events:          events,
+		metrics:         metrics,
+		gachaConfig:     gachaConfig,
+		logger:          logger,
+	}
+}
+
+// PullCost returns the currency cost of a single or ten pull
+func (s *EconomyService) PullCost(pullType string) (int, error) {
+	switch pullType {
+	case models.PullTypeSingle:
+		return s.gachaConfig.SinglePullCost, nil
+	case models.PullTypeTen:
+		return s.gachaConfig.TenPullCost, nil
+	}
+	return 0, fmt.Errorf("%w: unknown pull type %q", ErrInvalidPull, pullType)
+}
+
+// Pull charges a user for a single or ten pull and performs it, paying with
+// pull tickets when the user holds enough. It returns a *LimitError when the
+// pull would exceed the user's spending limit and ErrInsufficientCurrency
+// when the user cannot afford it.
+func (s *EconomyService) Pull(ctx context.Context, user *models.User, pullType string) (models.GachaResult, error) {
+	ctx, span := startSpan(ctx, "EconomyService.Pull")
+	defer span.End()
+
+	return s.pull(ctx, user, pullType, models.PaymentAuto)
+}
+
+// pull performs a single or ten pull paid as payment allows
+func (s *EconomyService) pull(ctx context.Context, user *models.User, pullType, payment string) (models.GachaResult, error) {
+	cost, err := s.PullCost(pullType)
+	if err != nil {
+		return models.GachaResult{}, err
+	}
+
+	pulls := 1
+	if pullType == models.PullTypeTen {
+		pulls = 10
+	}
+	return s.settle(ctx, user, pullOrder{
+		pullType: pullType,
+		pulls:    pulls,
+		cost:     cost,
+		payment:  payment,
+		perform: func(engine *GachaService) []models.Character {
+			if pullType == models.PullTypeTen {
+				return engine.PerformTenPull(ctx, user)
+			}
+			return []models.Character{engine.PerformSinglePull(ctx, user)}
+		},
+	})
+}
+
+// PullMany charges a user for count single pulls and performs them in one
+// transaction, stopping after the first character matching the request's stop
+// condition and refunding the pulls not performed. A count of one without a
+// stop condition is a single pull. Pulls are paid with one ticket each when
+// the request's payment allows and the user holds enough, otherwise with
+// currency; ErrInsufficientTickets is returned when tickets were required.
+// Requests outside the configured bounds return ErrInvalidPull.
+func (s *EconomyService) PullMany(ctx context.Context, user *models.User, req models.PullRequest) (models.GachaResult, error) {
+	ctx, span := startSpan(ctx, "EconomyService.PullMany")
+	defer span.End()
+
+	count := req.Count
+	if count == 0 {
+		count = 1
+	}
+	if count < 1 || count > s.gachaConfig.MaxPullCount {
+		return models.GachaResult{}, fmt.Errorf("%w: count must be between 1 and %d", ErrInvalidPull, s.gachaConfig.MaxPullCount)
+	}
+	if stop := req.StopOn; stop != nil {
+		if stop.Rarity == 0 && stop.CharacterID == 0 {
+			return models.GachaResult{}, fmt.Errorf("%w: stop condition needs a rarity or character ID", ErrInvalidPull)
+		}
+		if stop.CharacterID != 0 && !s.inPool(stop.CharacterID) {
+			return models.GachaResult{}, fmt.Errorf("%w: character %d is not in the pool", ErrInvalidPull, stop.CharacterID)
+		}
+	}
+	payment := req.Payment
+	switch payment {
+	case "":
+		payment = models.PaymentAuto
+	case models.PaymentAuto, models.PaymentTickets, models.PaymentCurrency:
+	default:
+		return models.GachaResult{}, fmt.Errorf("%w: unknown payment %q", ErrInvalidPull, payment)
+	}
+	if count == 1 && req.StopOn == nil {
+		return s.pull(ctx, user, models.PullTypeSingle, payment)
+	}
+
+	return s.settle(ctx, user, pullOrder{
+		pullType: models.PullTypeMulti,
+		pulls:    count,
+		cost:     count * s.gachaConfig.SinglePullCost,
+		payment:  payment,
+		count:    count,
+		stopOn:   req.StopOn,
+		perform: func(engine *GachaService) []models.Character {
+			return engine.PerformMultiPull(ctx, user, count, req.StopOn)
+		},
+	})
+}
+
+// pullOrder describes one pull transaction
+type pullOrder struct {
+	pullType string
+	pulls    int                   // Pulls paid for, one ticket each when paid in tickets
+	cost     int                   // Currency charged up front when not paid in tickets
+	payment  string                // How the pulls may be paid
+	count    int                   // Pulls requested, for multi pulls
+	stopOn   *models.StopCondition // For multi pulls
+	perform  func(engine *GachaService) []models.Character
+}
+
+// settle runs a pull transaction under the user's lock, then publishes the
+// pull and any characters newly added to the inventory once it has committed
+func (s *EconomyService) settle(ctx context.Context, user *models.User, order pullOrder) (models.GachaResult, error) {
+	unlock := s.lockUser(user.Username)
+	result, err := s.transact(ctx, user, order)
+	unlock()
+	if err != nil {
+		return result, err
+	}
+
+	s.events.Publish(ctx, Event{Kind: EventPull, User: user, Characters: result.Characters})
+	var added []models.Character
+	for i, char := range result.Characters {
+		if result.IsNew[i] {
+			added = append(added, char)
+		}
+	}
+	if len(added) > 0 {
+		s.events.Publish(ctx, Event{Kind: EventInventory, User: user, Characters: added})
+	}
+	return result, nil
+}
+
+// transact takes payment for a pull order, performs the pulls and refunds
+// the pulls that perform skipped
+func (s *EconomyService) transact(ctx context.Context, user *models.User, order pullOrder) (models.GachaResult, error) {
+	bannerID := models.DefaultBanner().ID
+	tickets, err := s.chargePull(user, bannerID, order)
+	if err != nil {
+		return models.GachaResult{}, err
+	}
+
+	start := time.Now()
+	engine := s.gachaService
+	random, proof := s.fairnessService.Draw(user, order.pullType)
+	if random != nil {
+		engine = engine.WithSource(random)
+		proof.PityThreshold = s.gachaService.PityThreshold()
+		proof.BannerID = bannerID
+		proof.Count = order.count
+		proof.StopOn = order.stopOn
+	}
+
+	characters := order.perform(engine)
+	refund := 0
+	if skipped := order.pulls - len(characters); skipped > 0 {
+		if tickets != nil {
+			refundTickets(user, bannerID, tickets, skipped)
+		} else {
+			refund = skipped * order.cost / order.pulls
+			user.AddCurrency(refund)
+			s.limitService.Refund(user, SpendPull, refund)
+		}
+	}
+
+	spent := 0
+	if tickets == nil {
+		spent = order.cost - refund
+		s.metrics.CurrencySpent(SpendPull, spent)
+	}
+	for itemID, quantity := range tickets {
+		s.metrics.ItemsSpent(SpendPull, itemID, quantity)
+	}
+	s.metrics.ObservePull(bannerID, order.pullType, characters, time.Since(start))
+
+	result := models.GachaResult{
+		Characters:   characters,
+		IsNew:        make([]bool, len(characters)),
+		Timestamp:    time.Now().Unix(),
+		Proof:        proof,
+		Refunded:     refund,
+		StoppedEarly: len(characters) < order.pulls,
+		TicketsUsed:  tickets,
+	}
+	ssr := 0
+	for i, char := range characters {
+		result.IsNew[i] = user.AddCharacter(char)
+		if char.Rarity == 5 {
+			ssr++
+		}
+	}
+
+	logging.FromContext(ctx, s.logger).InfoContext(ctx, "pull", logging.BannerIDKey, bannerID,
+		"type", order.pullType, "count", len(characters), "ssr", ssr, "refunded", refund, "tickets", tickets != nil)
+	s.auditService.RecordPull(ctx, user, order.pullType, spent, tickets, characters)
+
+	return result, nil
+}
+
+// chargePull takes payment for a pull order: in the banner's tickets when the
+// order allows and the user holds enough, otherwise in currency against the
+// spending limit. It returns the tickets taken, nil for a currency payment.
+func (s *EconomyService) chargePull(user *models.User, bannerID string, order pullOrder) (map[string]int, error) {
+	if order.payment != models.PaymentCurrency {
+		if tickets := takeTickets(user, bannerID, order.pulls); tickets != nil {
+			return tickets, nil
+		}
+		if order.payment == models.PaymentTickets {
+			return nil, ErrInsufficientTickets
+		}
+	}
+
+	if err := s.limitService.Charge(user, SpendPull, order.cost); err != nil {
+		return nil, err
+	}
+	if !user.DeductCurrency(order.cost) {
+		s.limitService.Refund(user, SpendPull, order.cost)
+		return nil, ErrInsufficientCurrency
+	}
+	return nil, nil
+}
+
+// takeTickets removes count pull tickets accepted by a banner from a user,
+// its own tickets first, returning how many of each it took, or nil without
+// taking any when the user holds too few
+func takeTickets(user *models.User, bannerID string, count int) map[string]int {
+	taken := make(map[string]int)
+	remaining := count
+	for _, item := range models.PullTicketsFor(bannerID) {
+		if n := min(user.ItemCount(item.ID), remaining); n > 0 {
+			taken[item.ID] = n
+			remaining -= n
+		}
+	}
+	if remaining > 0 {
+		return nil
+	}
+
+	for itemID, n := range taken {
+		user.RemoveItems(itemID, n)
+	}
+	return taken
+}
+
+// refundTickets returns count tickets taken by takeTickets, in the reverse
+// order they were taken, and deducts them from taken
+func refundTickets(user *models.User, bannerID string, taken map[string]int, count int) {
+	order := models.PullTicketsFor(bannerID)
+	for i := len(order) - 1; i >= 0 && count > 0; i-- {
+		itemID := order[i].ID
+		n := min(taken[itemID], count)
+		if n == 0 {
+			continue
+		}
+		user.AddItems(itemID, n)
+		count -= n
+		if taken[itemID] -= n; taken[itemID] == 0 {
+			delete(taken, itemID)
+		}
+	}
+}
+
+// Purchase adds purchased currency to a user, returning ErrInvalidAmount
+// unless the amount is positive and a *LimitError when it would exceed the
+// user's spending limit
+func (s *EconomyService) Purchase(ctx context.Context, user *models.User, amount int) error {
+	ctx, span := startSpan(ctx, "EconomyService.Purchase")
+	defer span.End()
+
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	unlock := s.lockUser(user.Username)
+	defer unlock()
+
+	if err := s.limitService.Charge(user, SpendPurchase, amount); err != nil {
+		return err
+	}
+
+	user.AddCurrency(amount)
+	s.metrics.CurrencyGranted(SpendPurchase, amount)
+	logging.FromContext(ctx, s.logger).InfoContext(ctx, "currency purchased", "amount", amount, "balance", user.Currency)
+	s.auditService.RecordCurrency(ctx, models.AuditPurchase, models.ActorUserPrefix+user.Username, user, amount, "")
+
+	return nil
+}
+
+// Grant adds a reward's currency and items to a user on behalf of actor,
+// recording source in metrics and reason in the audit log. It returns
+// ErrUnknownItem, granting nothing, when the reward names an unknown item.
+func (s *EconomyService) Grant(ctx context.Context, user *models.User, actor, source string, reward models.Reward, reason string) error {
+	ctx, span := startSpan(ctx, "EconomyService.Grant")
+	defer span.End()
+
+	for itemID, quantity := range reward.Items {
+		if _, ok := models.FindItem(itemID); !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownItem, itemID)
+		}
+		if quantity <= 0 {
+			return fmt.Errorf("item %q: quantity must be positive", itemID)
+		}
+	}
+
+	unlock := s.lockUser(user.Username)
+	defer unlock()
+
+	logger := logging.FromContext(ctx, s.logger)
+	if reward.Currency > 0 {
+		user.AddCurrency(reward.Currency)
+		s.metrics.CurrencyGranted(source, reward.Currency)
+		logger.InfoContext(ctx, "currency granted", "source", source, "amount", reward.Currency, "reason", reason)
+		s.auditService.RecordCurrency(ctx, models.AuditGrant, actor, user, reward.Currency, reason)
+	}
+	for itemID, quantity := range reward.Items {
+		user.AddItems(itemID, quantity)
+		s.metrics.ItemsGranted(source, itemID, quantity)
+		logger.InfoContext(ctx, "items granted", "source", source, "item", itemID, "quantity", quantity, "reason", reason)
+		s.auditService.RecordItems(ctx, models.AuditItemGrant, actor, user, models.AuditItemDetails{
+			ItemID:   itemID,
+			Quantity: quantity,
+			Source:   source,
+			Reason:   reason,
+		})
+	}
+	return nil
+}
+
+// Refund returns currency a user spent on pulls on behalf of actor, lifting
+// it from the user's monthly spending. It returns ErrInvalidAmount unless the
+// amount is positive and ErrRefundExceedsSpending, refunding nothing, for
+// more than the user spent on pulls this month.
+func (s *EconomyService) Refund(ctx context.Context, user *models.User, actor string, amount int, reason string) error {
+	ctx, span := startSpan(ctx, "EconomyService.Refund")
+	defer span.End()
+
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	unlock := s.lockUser(user.Username)
+	defer unlock()
+
+	if err := s.limitService.RefundSpent(user, amount); err != nil {
+		return err
+	}
+
+	user.AddCurrency(amount)
+	s.metrics.CurrencyGranted(SourceRefund, amount)
+	logging.FromContext(ctx, s.logger).InfoContext(ctx, "currency refunded", "amount", amount, "reason", reason, "balance", user.Currency)
+	s.auditService.RecordCurrency(ctx, models.AuditRefund, actor, user, amount, reason)
+
+	return nil
+}
+
+// Spend deducts currency a user pays for something other than pulls or the
+// shop, recording source in metrics and reason in the audit log. It counts
+// toward the pull spending limit, returning ErrInvalidAmount unless the amount
+// is positive, a *LimitError when it would exceed the limit and
+// ErrInsufficientCurrency when the user cannot afford it.
+func (s *EconomyService) Spend(ctx context.Context, user *models.User, source string, amount int, reason string) error {
+	ctx, span := startSpan(ctx, "EconomyService.Spend")
+	defer span.End()
+
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	unlock := s.lockUser(user.Username)
+	defer unlock()
+
+	if err := s.limitService.Charge(user, SpendPull, amount); err != nil {
+		return err
+	}
+	if !user.DeductCurrency(amount) {
+		s.limitService.Refund(user, SpendPull, amount)
+		return ErrInsufficientCurrency
+	}
+
+	s.metrics.CurrencySpent(source, amount)
+	logging.FromContext(ctx, s.logger).InfoContext(ctx, "currency spent", "source", source, "amount", amount, "reason", reason, "balance", user.Currency)
+	s.auditService.RecordCurrency(ctx, models.AuditSpend, models.ActorUserPrefix+user.Username, user, amount, reason)
+
+	return nil
+}
+
+// Buy exchanges currency for a shop offer's items. The price counts toward
+// the user's pull spending limit, as the items pay for pulls; it returns a
+// *LimitError when it would exceed the limit, ErrInsufficientCurrency when
+// the user cannot afford it and ErrUnknownOffer for an unknown offer.
+func (s *EconomyService) Buy(ctx context.Context, user *models.User, offerID string) (models.ShopOffer, error) {
+	ctx, span := startSpan(ctx, "EconomyService.Buy")
+	defer span.End()
+
+	offer, ok := models.FindShopOffer(offerID)
+	if !ok {
+		return offer, fmt.Errorf("%w: %q", ErrUnknownOffer, offerID)
+	}
+
+	unlock := s.lockUser(user.Username)
+	defer unlock()
+
+	if err := s.limitService.Charge(user, SpendPull, offer.Price); err != nil {
+		return offer, err
+	}
+	if !user.DeductCurrency(offer.Price) {
+		s.limitService.Refund(user, SpendPull, offer.Price)
+		return offer, ErrInsufficientCurrency
+	}
+
+	user.AddItems(offer.ItemID, offer.Quantity)
+	s.metrics.CurrencySpent(SourceShop, offer.Price)
+	s.metrics.ItemsGranted(SourceShop, offer.ItemID, offer.Quantity)
+	logging.FromContext(ctx, s.logger).InfoContext(ctx, "shop purchase", "offer", offer.ID, "price", offer.Price, "balance", user.Currency)
+	s.auditService.RecordItems(ctx, models.AuditShopBuy, models.ActorUserPrefix+user.Username, user, models.AuditItemDetails{
+		ItemID:   offer.ItemID,
+		Quantity: offer.Quantity,
+		OfferID:  offer.ID,
+		Price:    offer.Price,
+	})
+
+	return offer, nil
+}
+
+// Snapshot returns a copy of the user taken under the user's lock, for
+// building responses while other transactions of the user run
+func (s *EconomyService) Snapshot(user *models.User) models.User {
+	unlock := s.lockUser(user.Username)
+	defer unlock()
+	return user.Clone()
+}
+
+// Update runs fn under the user's lock, for services that keep their own
+// progress on the user alongside its balances
+func (s *EconomyService) Update(user *models.User, fn func()) {
+	unlock := s.lockUser(user.Username)
+	defer unlock()
+	fn()
+}
+
+// lockUser serializes the transactions of one user, returning the unlock function
+func (s *EconomyService) lockUser(username string) func() {
+	lock, _ := s.userLocks.LoadOrStore(username, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// inPool reports whether a character can be pulled
+func (s *EconomyService) inPool(id int) bool {
+	for _, char := range s.gachaService.GetCharacterPool() {
+		if char.ID == id {
+			return true
+		}
+	}
+	return false
+}
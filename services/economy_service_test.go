@@ -0,0 +1,230 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gacha/config"
+	"gacha/internal/testenv"
+	"gacha/models"
+	"gacha/services"
+)
+
+func TestPullManyStopsEarlyAndRefunds(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	service := env.Economy
+	cost := env.Config.Gacha.SinglePullCost
+	user := &models.User{Username: "stop", Currency: 100 * cost}
+
+	// R is the most likely rarity, so the stop is all but certain well before 100 pulls
+	result, err := service.PullMany(context.Background(), user, models.PullRequest{
+		Count:  100,
+		StopOn: &models.StopCondition{Rarity: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pulled := len(result.Characters)
+	if pulled == 0 || pulled == 100 {
+		t.Fatalf("pulled %d characters, want an early stop", pulled)
+	}
+	if last := result.Characters[pulled-1]; last.Rarity != 3 {
+		t.Errorf("stopped on rarity %d, want 3", last.Rarity)
+	}
+	if !result.StoppedEarly || result.Refunded != (100-pulled)*cost {
+		t.Errorf("stoppedEarly %v refunded %d, want %d", result.StoppedEarly, result.Refunded, (100-pulled)*cost)
+	}
+	if want := (100 - pulled) * cost; user.Currency != want {
+		t.Errorf("balance %d after refund, want %d", user.Currency, want)
+	}
+}
+
+func TestPullManyRejectsOutOfBounds(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	service := env.Economy
+	user := &models.User{Username: "bounds", Currency: 1 << 30}
+
+	requests := map[string]models.PullRequest{
+		"over max":          {Count: env.Config.Gacha.MaxPullCount + 1},
+		"negative":          {Count: -1},
+		"empty condition":   {Count: 10, StopOn: &models.StopCondition{}},
+		"unknown character": {Count: 10, StopOn: &models.StopCondition{CharacterID: -1}},
+	}
+	for name, req := range requests {
+		if _, err := service.PullMany(context.Background(), user, req); !errors.Is(err, services.ErrInvalidPull) {
+			t.Errorf("%s: got %v, want services.ErrInvalidPull", name, err)
+		}
+	}
+	if user.Currency != 1<<30 {
+		t.Errorf("rejected pulls charged %d", 1<<30-user.Currency)
+	}
+}
+
+func TestPullsSpendBannerTicketsBeforeGenericAndCurrency(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	service := env.Economy
+	user := &models.User{Username: "tickets", Currency: 10000}
+	user.AddItems(models.ItemStandardTicket, 4)
+	user.AddItems(models.ItemGenericTicket, 8)
+
+	result, err := service.Pull(context.Background(), user, models.PullTypeTen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TicketsUsed[models.ItemStandardTicket] != 4 || result.TicketsUsed[models.ItemGenericTicket] != 6 {
+		t.Errorf("used tickets %v, want 4 standard and 6 generic", result.TicketsUsed)
+	}
+	if user.Currency != 10000 || user.ItemCount(models.ItemGenericTicket) != 2 {
+		t.Errorf("balance %d and %d generic tickets left", user.Currency, user.ItemCount(models.ItemGenericTicket))
+	}
+
+	// Two tickets cannot pay for ten pulls, so currency does unless tickets are required
+	if _, err := service.PullMany(context.Background(), user, models.PullRequest{Count: 10, Payment: models.PaymentTickets}); !errors.Is(err, services.ErrInsufficientTickets) {
+		t.Errorf("ticket-only pull returned %v", err)
+	}
+	if _, err := service.Pull(context.Background(), user, models.PullTypeTen); err != nil {
+		t.Fatal(err)
+	}
+	if user.Currency != 10000-env.Config.Gacha.TenPullCost || user.ItemCount(models.ItemGenericTicket) != 2 {
+		t.Errorf("balance %d and %d generic tickets after a currency pull", user.Currency, user.ItemCount(models.ItemGenericTicket))
+	}
+
+	// Explicitly paying with currency leaves tickets untouched
+	if _, err := service.PullMany(context.Background(), user, models.PullRequest{Count: 1, Payment: models.PaymentCurrency}); err != nil {
+		t.Fatal(err)
+	}
+	if user.ItemCount(models.ItemGenericTicket) != 2 {
+		t.Errorf("currency pull spent tickets")
+	}
+}
+
+func TestPullManyRefundsSkippedTickets(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	service := env.Economy
+	user := &models.User{Username: "ticket-stop"}
+	user.AddItems(models.ItemStandardTicket, 50)
+	user.AddItems(models.ItemGenericTicket, 50)
+
+	result, err := service.PullMany(context.Background(), user, models.PullRequest{
+		Count:  100,
+		StopOn: &models.StopCondition{Rarity: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pulled := len(result.Characters)
+	if !result.StoppedEarly || result.Refunded != 0 {
+		t.Errorf("stoppedEarly %v refunded %d currency", result.StoppedEarly, result.Refunded)
+	}
+	// Generic tickets were taken last, so they are returned first
+	if result.TicketsUsed[models.ItemStandardTicket] != min(pulled, 50) || result.TicketsUsed[models.ItemGenericTicket] != max(pulled-50, 0) {
+		t.Errorf("used tickets %v after %d pulls", result.TicketsUsed, pulled)
+	}
+	if held := user.ItemCount(models.ItemStandardTicket) + user.ItemCount(models.ItemGenericTicket); held != 100-pulled {
+		t.Errorf("%d tickets left after %d pulls", held, pulled)
+	}
+}
+
+func TestBuyChargesPullLimit(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	service := env.Economy
+	offer := models.GetShopOffers()[0]
+	user := &models.User{Username: "shopper", Currency: 10 * offer.Price, SelfLimit: offer.Price}
+
+	if _, err := service.Buy(context.Background(), user, offer.ID); err != nil {
+		t.Fatal(err)
+	}
+	if user.ItemCount(offer.ItemID) != offer.Quantity || user.Currency != 9*offer.Price {
+		t.Errorf("holding %d items and %d currency after buying", user.ItemCount(offer.ItemID), user.Currency)
+	}
+
+	var limitErr *services.LimitError
+	if _, err := service.Buy(context.Background(), user, offer.ID); !errors.As(err, &limitErr) {
+		t.Errorf("buy over the limit returned %v", err)
+	}
+	if _, err := service.Buy(context.Background(), user, "no-such-offer"); !errors.Is(err, services.ErrUnknownOffer) {
+		t.Errorf("unknown offer returned %v", err)
+	}
+}
+
+func TestRefundIsBoundedByMonthlySpending(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	service := env.Economy
+	cost := env.Config.Gacha.SinglePullCost
+	user := &models.User{Username: "refund", AgeBracket: models.AgeBracketUnder13, Currency: cost}
+	ctx := context.Background()
+
+	if _, err := service.Pull(ctx, user, models.PullTypeSingle); err != nil {
+		t.Fatal(err)
+	}
+
+	// Refunding more than was spent would lift the bracket cap
+	if err := service.Refund(ctx, user, models.ActorAdminPrefix+"ops", cost+1, "too much"); !errors.Is(err, services.ErrRefundExceedsSpending) {
+		t.Fatalf("oversized refund returned %v", err)
+	}
+	if user.Currency != 0 || user.Spending.Spent != cost {
+		t.Fatalf("rejected refund left balance %d, spent %d", user.Currency, user.Spending.Spent)
+	}
+
+	if err := service.Refund(ctx, user, models.ActorAdminPrefix+"ops", cost, "duplicate charge"); err != nil {
+		t.Fatal(err)
+	}
+	if user.Currency != cost || user.Spending.Spent != 0 {
+		t.Errorf("refund left balance %d, spent %d", user.Currency, user.Spending.Spent)
+	}
+	entries := env.Audit.Query(models.AuditFilter{Action: models.AuditRefund})
+	if len(entries) != 1 || entries[0].Actor != models.ActorAdminPrefix+"ops" {
+		t.Errorf("audit holds refunds %+v", entries)
+	}
+}
+
+func TestSnapshotIsolatesConcurrentGrants(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	service := env.Economy
+	user := &models.User{Username: "snapshot"}
+	ctx := context.Background()
+	ticket := models.GetItems()[0].ID
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if err := service.Grant(ctx, user, "system", services.SourceAdmin, models.Reward{Items: map[string]int{ticket: 1}}, "race"); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		snapshot := service.Snapshot(user)
+		_ = snapshot.PullTickets()
+		if snapshot.Items != nil {
+			snapshot.Items[ticket] = -1
+		}
+	}
+	wg.Wait()
+
+	if snapshot := service.Snapshot(user); snapshot.ItemCount(ticket) != 100 {
+		t.Errorf("user holds %d tickets after 100 grants", snapshot.ItemCount(ticket))
+	}
+}
+
+func TestSpendRejectsNonPositiveAmounts(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	service := env.Economy
+	user := &models.User{Username: "spender", Currency: 100}
+
+	for _, amount := range []int{0, -100} {
+		if err := service.Spend(context.Background(), user, services.SourceSeason, amount, "test"); !errors.Is(err, services.ErrInvalidAmount) {
+			t.Errorf("spending %d returned %v", amount, err)
+		}
+	}
+	if user.Currency != 100 || user.Spending.Spent != 0 {
+		t.Errorf("rejected spends left balance %d, spent %d", user.Currency, user.Spending.Spent)
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"gacha/models"
+)
+
+// Domain event kinds
+const (
+	EventPull           = "pull"            // A user pulled characters
+	EventInventory      = "inventory"       // Characters were newly added to a user's inventory
+	EventLogin          = "login"           // A user claimed their daily login reward
+	EventMissionClaimed = "mission_claimed" // A user claimed a mission reward
+)
+
+// Event describes something that happened to a user, for subsystems such as
+// missions that react to it
+type Event struct {
+	Kind       string
+	User       *models.User
+	Characters []models.Character // Pulled for EventPull, newly owned for EventInventory
+	MissionID  string             // Claimed for EventMissionClaimed
+}
+
+// EventListener is called for each published event, with the context of the
+// operation that published it
+type EventListener func(ctx context.Context, event Event)
+
+// EventBus delivers domain events to their listeners synchronously, in the
+// order they subscribed
+type EventBus struct {
+	listeners []EventListener
+	mu        sync.RWMutex
+}
+
+// NewEventBus creates a new event bus
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe registers a listener for every event
+func (b *EventBus) Subscribe(listener EventListener) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, listener)
+}
+
+// Publish delivers an event to every listener
+func (b *EventBus) Publish(ctx context.Context, event Event) {
+	ctx, span := startSpan(ctx, "EventBus.Publish")
+	defer span.End()
+
+	b.mu.RLock()
+	listeners := make([]EventListener, len(b.listeners))
+	copy(listeners, b.listeners)
+	b.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(ctx, event)
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+
+	"gacha/engine"
+	"gacha/models"
+)
+
+// fairnessState is a user's active server seed commitment
+type fairnessState struct {
+	serverSeed string
+	clientSeed string
+	nonce      uint64 // Next nonce to use
+}
+
+// FairnessService manages provably fair server seed commitments
+type FairnessService struct {
+	enabled bool
+	states  map[string]*fairnessState
+	mu      sync.Mutex
+}
+
+// NewFairnessService creates a new fairness service
+func NewFairnessService(enabled bool) *FairnessService {
+	return &FairnessService{
+		enabled: enabled,
+		states:  make(map[string]*fairnessState),
+	}
+}
+
+// Enabled reports whether pulls are provably fair
+func (s *FairnessService) Enabled() bool {
+	return s.enabled
+}
+
+// Commitment returns the active commitment of a user
+func (s *FairnessService) Commitment(username string) models.FairnessCommitment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state(username).commitment()
+}
+
+// SetClientSeed changes the client seed mixed into future pulls
+func (s *FairnessService) SetClientSeed(username, clientSeed string) models.FairnessCommitment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.state(username)
+	state.clientSeed = clientSeed
+	return state.commitment()
+}
+
+// Rotate retires the active server seed, revealing it, and commits to a new one
+func (s *FairnessService) Rotate(username string) models.RevealedSeed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.state(username)
+	next := &fairnessState{
+		serverSeed: randomHex(32),
+		clientSeed: old.clientSeed,
+	}
+	s.states[username] = next
+
+	return models.RevealedSeed{
+		ServerSeed:     old.serverSeed,
+		ServerSeedHash: engine.HashServerSeed(old.serverSeed),
+		ClientSeed:     old.clientSeed,
+		NoncesUsed:     old.nonce,
+		Next:           next.commitment(),
+	}
+}
+
+// Draw consumes a nonce and returns the random source for one pull together
+// with its proof, or nil for both when provably fair mode is disabled
+func (s *FairnessService) Draw(user *models.User, pullType string) (engine.RandomSource, *models.PullProof) {
+	if !s.enabled {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.state(user.Username)
+	nonce := state.nonce
+	state.nonce++
+
+	proof := &models.PullProof{
+		ServerSeedHash: engine.HashServerSeed(state.serverSeed),
+		ClientSeed:     state.clientSeed,
+		Nonce:          nonce,
+		PityBefore:     user.PityCount,
+		PullType:       pullType,
+	}
+	return engine.NewHMACSource(state.serverSeed, state.clientSeed, nonce), proof
+}
+
+// state returns the user's commitment, creating it on first use; called with mu held
+func (s *FairnessService) state(username string) *fairnessState {
+	state, ok := s.states[username]
+	if !ok {
+		state = &fairnessState{
+			serverSeed: randomHex(32),
+			clientSeed: randomHex(8),
+		}
+		s.states[username] = state
+	}
+	return state
+}
+
+// commitment describes the state without revealing the server seed
+func (st *fairnessState) commitment() models.FairnessCommitment {
+	return models.FairnessCommitment{
+		ServerSeedHash: engine.HashServerSeed(st.serverSeed),
+		ClientSeed:     st.clientSeed,
+		NextNonce:      st.nonce,
+	}
+}
+
+// randomHex returns n cryptographically random bytes, hex encoded
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
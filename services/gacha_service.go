@@ -1,100 +1,104 @@
 package services
 
 import (
+	"context"
+
+	"gacha/engine"
 	"gacha/models"
-	"math/rand"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// GachaService handles gacha logic
+// tracer creates the spans of service operations
+var tracer = otel.Tracer("gacha/services")
+
+// startSpan starts a span for a service operation as a child of the request
+// or message being traced. Without a recording parent, as in the simulator's
+// hot loop, it returns the parent as is and records nothing.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return ctx, parent
+	}
+	return tracer.Start(ctx, name)
+}
+
+// GachaService performs pulls with the engine, tracing each one
 type GachaService struct {
-	characterPool []models.Character
+	engine *engine.Engine
 }
 
-// NewGachaService creates a new gacha service
-func NewGachaService() *GachaService {
-	return &GachaService{
-		characterPool: models.GetCharacterPool(),
-	}
+// NewGachaService creates a new gacha service over the global character pool
+// drawing from the given random source
+func NewGachaService(random engine.RandomSource) *GachaService {
+	return NewGachaServiceWithPool(models.GetCharacterPool(), engine.DefaultPityThreshold, random)
 }
 
-// PerformSinglePull performs a single gacha pull
-func (s *GachaService) PerformSinglePull(user *models.User) models.Character {
-	user.IncrementPity()
+// NewGachaServiceWithPool creates a new gacha service over a custom character pool and pity threshold
+func NewGachaServiceWithPool(pool []models.Character, pityThreshold int, random engine.RandomSource) *GachaService {
+	return &GachaService{engine: engine.New(pool, pityThreshold, random)}
+}
 
-	// Pity system: guaranteed SSR at 90 pulls
-	if user.PityCount >= 90 {
-		user.ResetPity()
-		return s.getRandomCharacterByRarity(5)
-	}
+// WithSource returns a copy of the service that draws from a different random source
+func (s *GachaService) WithSource(random engine.RandomSource) *GachaService {
+	return &GachaService{engine: s.engine.WithSource(random)}
+}
 
-	// Normal gacha logic
-	totalRate := 0.0
-	for _, char := range s.characterPool {
-		totalRate += char.Rate
-	}
+// SetObserver sets the observer notified of pull mechanics
+func (s *GachaService) SetObserver(observer engine.PullObserver) {
+	s.engine.SetObserver(observer)
+}
 
-	roll := rand.Float64() * totalRate
-	currentRate := 0.0
-
-	for _, char := range s.characterPool {
-		currentRate += char.Rate
-		if roll <= currentRate {
-			if char.Rarity == 5 {
-				user.ResetPity() // Reset pity when SSR obtained
-			}
-			return char
-		}
-	}
+// PerformSinglePull performs a single gacha pull
+func (s *GachaService) PerformSinglePull(ctx context.Context, user *models.User) models.Character {
+	_, span := startSpan(ctx, "GachaService.PerformSinglePull")
+	defer span.End()
 
-	return s.characterPool[len(s.characterPool)-1]
+	char := s.engine.Single(user)
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("gacha.rarity", char.Rarity), attribute.Int("gacha.pity", user.PityCount))
+	}
+	return char
 }
 
 // PerformTenPull performs a ten-pull with guaranteed SR
-func (s *GachaService) PerformTenPull(user *models.User) []models.Character {
-	var characters []models.Character
-	hasSR := false
-
-	for i := 0; i < 10; i++ {
-		char := s.PerformSinglePull(user)
-
-		if i == 9 && !hasSR {
-			// Last pull: force SR if no SR+ obtained
-			for _, c := range characters {
-				if c.Rarity >= 4 {
-					hasSR = true
-					break
-				}
-			}
-			if !hasSR {
-				char = s.getRandomCharacterByRarity(4)
-			}
-		}
-
-		if char.Rarity >= 4 {
-			hasSR = true
-		}
-
-		characters = append(characters, char)
+func (s *GachaService) PerformTenPull(ctx context.Context, user *models.User) []models.Character {
+	_, span := startSpan(ctx, "GachaService.PerformTenPull")
+	defer span.End()
+
+	characters := s.engine.Ten(user)
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("gacha.pity", user.PityCount))
 	}
+	return characters
+}
 
+// PerformMultiPull performs up to count single pulls, stopping after the
+// first character that matches stopOn
+func (s *GachaService) PerformMultiPull(ctx context.Context, user *models.User, count int, stopOn *models.StopCondition) []models.Character {
+	_, span := startSpan(ctx, "GachaService.PerformMultiPull")
+	defer span.End()
+
+	characters := s.engine.Multi(user, count, stopOn)
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("gacha.count", count), attribute.Int("gacha.pulled", len(characters)), attribute.Int("gacha.pity", user.PityCount))
+	}
 	return characters
 }
 
 // GetCharacterPool returns the character pool
 func (s *GachaService) GetCharacterPool() []models.Character {
-	return s.characterPool
+	return s.engine.Pool()
 }
 
-// getRandomCharacterByRarity gets a random character of specific rarity
-func (s *GachaService) getRandomCharacterByRarity(rarity int) models.Character {
-	var filtered []models.Character
-	for _, char := range s.characterPool {
-		if char.Rarity == rarity {
-			filtered = append(filtered, char)
-		}
-	}
-	if len(filtered) == 0 {
-		return s.characterPool[0]
-	}
-	return filtered[rand.Intn(len(filtered))]
+// PityThreshold returns the number of pulls that guarantees an SSR
+func (s *GachaService) PityThreshold() int {
+	return s.engine.PityThreshold()
+}
+
+// RarityRates returns the base per-pull probability of each rarity from the pool weights
+func (s *GachaService) RarityRates() map[int]float64 {
+	return s.engine.RarityRates()
 }
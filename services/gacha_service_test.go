@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"gacha/config"
+	"gacha/engine"
+	"gacha/models"
+)
+
+// Chi-square critical values at p = 0.001, indexed by degrees of freedom.
+// A correct engine fails a test at this level about once in a thousand runs,
+// but the seeds are fixed so the suite is deterministic.
+var chiSquareCritical = map[int]float64{
+	1:  10.828,
+	2:  13.816,
+	3:  16.266,
+	10: 29.588,
+}
+
+// chiSquare returns the chi-square statistic of observed counts against expected probabilities
+func chiSquare(observed []int, expected []float64) float64 {
+	total := 0
+	for _, n := range observed {
+		total += n
+	}
+
+	stat := 0.0
+	for i, n := range observed {
+		e := expected[i] * float64(total)
+		stat += (float64(n) - e) * (float64(n) - e) / e
+	}
+	return stat
+}
+
+// assertFits fails the test when observed counts reject the expected distribution
+func assertFits(t *testing.T, name string, observed []int, expected []float64) {
+	t.Helper()
+
+	df := len(observed) - 1
+	critical, ok := chiSquareCritical[df]
+	if !ok {
+		t.Fatalf("%s: no critical value for %d degrees of freedom", name, df)
+	}
+
+	if stat := chiSquare(observed, expected); stat > critical {
+		t.Errorf("%s: chi-square %.2f exceeds %.3f (df=%d); observed %v, expected %v",
+			name, stat, critical, df, observed, expected)
+	}
+}
+
+// disclosedRates returns the published SSR, SR and R rates
+func disclosedRates() []float64 {
+	cfg := config.LoadConfig()
+	return []float64{cfg.Gacha.SSRRate, cfg.Gacha.SRRate, cfg.Gacha.RRate}
+}
+
+// consolidatedSSRRate is the long-run SSR rate once pity is factored in: the
+// inverse of the expected number of pulls per SSR when an SSR is guaranteed on
+// pull pityThreshold
+func consolidatedSSRRate(ssrRate float64, pityThreshold int) float64 {
+	expectedPulls := (1 - math.Pow(1-ssrRate, float64(pityThreshold))) / ssrRate
+	return 1 / expectedPulls
+}
+
+// rarityIndex maps a rarity to its position in disclosedRates
+func rarityIndex(t *testing.T, rarity int) int {
+	switch rarity {
+	case 5:
+		return 0
+	case 4:
+		return 1
+	case 3:
+		return 2
+	}
+	t.Fatalf("unexpected rarity %d", rarity)
+	return -1
+}
+
+func newSeededService(seed uint64) *GachaService {
+	return NewGachaService(engine.NewSeededSource(seed))
+}
+
+func TestPoolWeightsMatchDisclosedRates(t *testing.T) {
+	rates := newSeededService(1).RarityRates()
+	disclosed := disclosedRates()
+
+	for rarity, want := range map[int]float64{5: disclosed[0], 4: disclosed[1], 3: disclosed[2]} {
+		if got := rates[rarity]; math.Abs(got-want) > 1e-9 {
+			t.Errorf("rarity %d: pool weights give %.6f, disclosed rate is %.6f", rarity, got, want)
+		}
+	}
+}
+
+func TestSinglePullMatchesDisclosedRates(t *testing.T) {
+	const samples = 200000
+	service := newSeededService(34)
+	user := &models.User{}
+
+	observed := make([]int, 3)
+	for i := 0; i < samples; i++ {
+		user.ResetPity() // Keep pity out of the base rate
+		char := service.PerformSinglePull(context.Background(), user)
+		observed[rarityIndex(t, char.Rarity)]++
+	}
+
+	assertFits(t, "single pull rarity", observed, disclosedRates())
+}
+
+func TestSinglePullMatchesCharacterWeights(t *testing.T) {
+	const samples = 200000
+	service := newSeededService(35)
+	pool := service.GetCharacterPool()
+	user := &models.User{}
+
+	totalRate := 0.0
+	index := make(map[int]int)
+	for i, char := range pool {
+		totalRate += char.Rate
+		index[char.ID] = i
+	}
+	expected := make([]float64, len(pool))
+	for i, char := range pool {
+		expected[i] = char.Rate / totalRate
+	}
+
+	observed := make([]int, len(pool))
+	for i := 0; i < samples; i++ {
+		user.ResetPity()
+		observed[index[service.PerformSinglePull(context.Background(), user).ID]]++
+	}
+
+	assertFits(t, "single pull per character", observed, expected)
+}
+
+func TestSinglePullConsolidatedSSRRate(t *testing.T) {
+	const samples = 1000000
+	cfg := config.LoadConfig()
+	service := newSeededService(36)
+	user := &models.User{}
+
+	ssr := 0
+	sincePity := 0
+	for i := 0; i < samples; i++ {
+		sincePity++
+		if service.PerformSinglePull(context.Background(), user).Rarity == 5 {
+			ssr++
+			sincePity = 0
+		}
+		if sincePity >= service.PityThreshold() {
+			t.Fatalf("pull %d: %d pulls without an SSR", i+1, sincePity)
+		}
+	}
+
+	rate := consolidatedSSRRate(cfg.Gacha.SSRRate, service.PityThreshold())
+	assertFits(t, "consolidated SSR rate", []int{ssr, samples - ssr}, []float64{rate, 1 - rate})
+}
+
+func TestTenPullConsolidatedSSRRate(t *testing.T) {
+	const batches = 100000
+	cfg := config.LoadConfig()
+	service := newSeededService(37)
+	user := &models.User{}
+
+	ssr := 0
+	sinceSSR := 0
+	for i := 0; i < batches; i++ {
+		for _, char := range service.PerformTenPull(context.Background(), user) {
+			sinceSSR++
+			if char.Rarity == 5 {
+				ssr++
+				sinceSSR = 0
+			}
+			if sinceSSR >= service.PityThreshold() {
+				t.Fatalf("ten pull %d: %d pulls without an SSR", i+1, sinceSSR)
+			}
+		}
+	}
+
+	samples := batches * 10
+	rate := consolidatedSSRRate(cfg.Gacha.SSRRate, service.PityThreshold())
+	assertFits(t, "ten pull consolidated SSR rate", []int{ssr, samples - ssr}, []float64{rate, 1 - rate})
+}
+
+func TestTenPullGuaranteesSR(t *testing.T) {
+	service := newSeededService(38)
+	user := &models.User{}
+
+	for i := 0; i < 50000; i++ {
+		characters := service.PerformTenPull(context.Background(), user)
+		if len(characters) != 10 {
+			t.Fatalf("ten pull %d returned %d characters", i+1, len(characters))
+		}
+
+		hasSR := false
+		for _, char := range characters {
+			if char.Rarity >= 4 {
+				hasSR = true
+			}
+		}
+		if !hasSR {
+			t.Fatalf("ten pull %d has no SR or better: %v", i+1, characters)
+		}
+	}
+}
+
+func TestTenPullKeepsSSROnLastPull(t *testing.T) {
+	// SR and SSR are too rare to pull, so only pity grants the SSR, on the last pull
+	pool := []models.Character{
+		{ID: 1, Rarity: 3, Rate: 1},
+		{ID: 2, Rarity: 4, Rate: 1e-12},
+		{ID: 3, Rarity: 5, Rate: 1e-12},
+	}
+	service := NewGachaServiceWithPool(pool, 10, engine.NewSeededSource(41))
+	user := &models.User{}
+
+	characters := service.PerformTenPull(context.Background(), user)
+	for i, char := range characters[:9] {
+		if char.Rarity != 3 {
+			t.Fatalf("pull %d has rarity %d", i+1, char.Rarity)
+		}
+	}
+	// The SR guarantee is met by the SSR rather than replacing it
+	if last := characters[9]; last.Rarity != 5 || user.PityCount != 0 {
+		t.Errorf("last pull has rarity %d with pity count %d, want the pity SSR", last.Rarity, user.PityCount)
+	}
+}
+
+func TestPityGuaranteesSSR(t *testing.T) {
+	service := newSeededService(39)
+	user := &models.User{PityCount: service.PityThreshold() - 1}
+
+	if char := service.PerformSinglePull(context.Background(), user); char.Rarity != 5 {
+		t.Errorf("pull at pity threshold returned rarity %d", char.Rarity)
+	}
+	if user.PityCount != 0 {
+		t.Errorf("pity count after pity SSR is %d, want 0", user.PityCount)
+	}
+}
+
+func TestSeededSourceReplaysPulls(t *testing.T) {
+	first := newSeededService(40)
+	second := newSeededService(40)
+	firstUser, secondUser := &models.User{}, &models.User{}
+
+	for i := 0; i < 1000; i++ {
+		a, b := first.PerformTenPull(context.Background(), firstUser), second.PerformTenPull(context.Background(), secondUser)
+		for j := range a {
+			if a[j].ID != b[j].ID {
+				t.Fatalf("ten pull %d, character %d: %d != %d", i+1, j+1, a[j].ID, b[j].ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gacha/config"
+	"gacha/logging"
+	"gacha/models"
+)
+
+// Kinds of spending checked against monthly limits
+const (
+	SpendPurchase = "purchase"
+	SpendPull     = "pull"
+)
+
+// ErrRefundExceedsSpending is returned for a refund of more than the user
+// spent on pulls this month
+var ErrRefundExceedsSpending = errors.New("refund exceeds this month's pull spending")
+
+// LimitError is returned when a purchase or pull would exceed a monthly limit
+type LimitError struct {
+	Kind      string
+	Limit     int
+	Used      int
+	Requested int
+	ResetsAt  time.Time
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("Monthly %s limit of %d reached", e.Kind, e.Limit)
+}
+
+// LimitService enforces monthly spending limits by age bracket and self-imposed caps
+type LimitService struct {
+	bracketCaps map[string]int
+	location    *time.Location
+	now         func() time.Time
+	logger      *slog.Logger
+	mu          sync.Mutex
+}
+
+// NewLimitService creates a new limit service
+func NewLimitService(cfg config.LimitsConfig, logger *slog.Logger) (*LimitService, error) {
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("load limits timezone: %w", err)
+	}
+
+	return &LimitService{
+		bracketCaps: cfg.BracketCaps,
+		location:    location,
+		now:         time.Now,
+		logger:      logger,
+	}, nil
+}
+
+// SetClock replaces the time source of monthly periods, letting tests cross
+// a reset
+func (s *LimitService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// ValidBracket checks if an age bracket is configured
+func (s *LimitService) ValidBracket(ageBracket string) bool {
+	_, ok := s.bracketCaps[ageBracket]
+	return ok
+}
+
+// Status returns a user's limits and spending this month
+func (s *LimitService) Status(user *models.User) models.LimitsResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.rollover(user, now)
+
+	return models.LimitsResponse{
+		AgeBracket:       user.AgeBracket,
+		BracketLimit:     s.bracketCaps[user.AgeBracket],
+		SelfLimit:        user.SelfLimit,
+		PendingSelfLimit: user.PendingSelfLimit,
+		EffectiveLimit:   s.effectiveLimit(user),
+		Period:           user.Spending.Period,
+		Purchased:        user.Spending.Purchased,
+		Spent:            user.Spending.Spent,
+		ResetsAt:         s.nextReset(now).Format(time.RFC3339),
+	}
+}
+
+// SetSelfLimit sets a user's self-imposed monthly limit, 0 removing it. A
+// lower limit applies at once, while raising or removing the limit waits for
+// the next monthly reset so that it cannot be lifted on impulse.
+func (s *LimitService) SetSelfLimit(user *models.User, limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Start the current period first, so that its rollover does not apply
+	// the pending limit at once
+	now := s.now()
+	s.rollover(user, now)
+
+	if stricter(limit, user.SelfLimit) || limit == user.SelfLimit {
+		user.SelfLimit = limit
+		user.PendingSelfLimit = nil
+		s.logger.Info("self limit set", logging.UserIDKey, user.ID, "limit", limit)
+		return
+	}
+
+	user.PendingSelfLimit = &limit
+	s.logger.Info("self limit raise scheduled", logging.UserIDKey, user.ID, "limit", limit, "from", s.nextReset(now))
+}
+
+// stricter reports whether limit a allows less spending than b, 0 being unlimited
+func stricter(a, b int) bool {
+	return a > 0 && (b == 0 || a < b)
+}
+
+// Charge records a purchase or pull spend, returning a *LimitError without
+// recording it if it would exceed the user's effective monthly limit
+func (s *LimitService) Charge(user *models.User, kind string, amount int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.rollover(user, now)
+
+	used := &user.Spending.Spent
+	if kind == SpendPurchase {
+		used = &user.Spending.Purchased
+	}
+
+	if limit := s.effectiveLimit(user); limit > 0 && *used+amount > limit {
+		s.logger.Info("spending limit reached",
+			logging.UserIDKey, user.ID, "kind", kind, "limit", limit, "used", *used, "requested", amount)
+		return &LimitError{
+			Kind:      kind,
+			Limit:     limit,
+			Used:      *used,
+			Requested: amount,
+			ResetsAt:  s.nextReset(now),
+		}
+	}
+
+	*used += amount
+	return nil
+}
+
+// Refund reverses a charge whose operation did not go through. A charge from
+// before the monthly reset is already gone, so spending never drops below 0.
+func (s *LimitService) Refund(user *models.User, kind string, amount int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rollover(user, s.now())
+	used := &user.Spending.Spent
+	if kind == SpendPurchase {
+		used = &user.Spending.Purchased
+	}
+	*used = max(*used-amount, 0)
+}
+
+// RefundSpent lifts a refund of pull spending from this month's spending,
+// returning ErrRefundExceedsSpending, and lifting nothing, for more than the
+// user spent this month so that refunds cannot raise the limit
+func (s *LimitService) RefundSpent(user *models.User, amount int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rollover(user, s.now())
+	if amount > user.Spending.Spent {
+		return fmt.Errorf("%w: %d refunded, %d spent", ErrRefundExceedsSpending, amount, user.Spending.Spent)
+	}
+	user.Spending.Spent -= amount
+	return nil
+}
+
+// effectiveLimit returns the stricter of the bracket cap and self limit, 0 for unlimited
+func (s *LimitService) effectiveLimit(user *models.User) int {
+	limit := s.bracketCaps[user.AgeBracket]
+	if user.SelfLimit > 0 && (limit == 0 || user.SelfLimit < limit) {
+		limit = user.SelfLimit
+	}
+	return limit
+}
+
+// rollover resets a user's spending when a new calendar month has started
+func (s *LimitService) rollover(user *models.User, now time.Time) {
+	period := now.In(s.location).Format("2006-01")
+	if user.Spending.Period != period {
+		user.Spending = models.Spending{Period: period}
+		if user.PendingSelfLimit != nil {
+			user.SelfLimit = *user.PendingSelfLimit
+			user.PendingSelfLimit = nil
+		}
+	}
+}
+
+// nextReset returns the start of the next calendar month
+func (s *LimitService) nextReset(now time.Time) time.Time {
+	local := now.In(s.location)
+	return time.Date(local.Year(), local.Month()+1, 1, 0, 0, 0, 0, s.location)
+}
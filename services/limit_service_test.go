@@ -0,0 +1,137 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gacha/config"
+	"gacha/internal/testenv"
+	"gacha/models"
+	"gacha/services"
+)
+
+// limitEnv returns services whose monthly limits reset in timezone
+func limitEnv(t *testing.T, timezone string) *testenv.Env {
+	t.Helper()
+	cfg := config.LoadConfig()
+	cfg.Limits.Timezone = timezone
+	return testenv.New(t, cfg)
+}
+
+func TestChargeEnforcesStricterLimit(t *testing.T) {
+	env := limitEnv(t, "UTC")
+	env.Clock.Set(time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC))
+	service := env.Limits
+	user := &models.User{AgeBracket: models.AgeBracket13To15, SelfLimit: 1000}
+
+	if err := service.Charge(user, services.SpendPurchase, 1000); err != nil {
+		t.Fatalf("charge within self limit: %v", err)
+	}
+
+	var limitErr *services.LimitError
+	if err := service.Charge(user, services.SpendPurchase, 1); !errors.As(err, &limitErr) {
+		t.Fatalf("charge over self limit returned %v", err)
+	}
+	if limitErr.Limit != 1000 || limitErr.Used != 1000 {
+		t.Errorf("limit error reports limit %d used %d", limitErr.Limit, limitErr.Used)
+	}
+
+	// Pulls are tracked separately from purchases
+	if err := service.Charge(user, services.SpendPull, 1000); err != nil {
+		t.Errorf("pull charge within limit: %v", err)
+	}
+}
+
+func TestChargeResetsAtMonthBoundaryInTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("timezone database unavailable")
+	}
+
+	env := limitEnv(t, "Asia/Tokyo")
+	// 23:00 on Jan 31 in Tokyo
+	env.Clock.Set(time.Date(2026, 1, 31, 14, 0, 0, 0, time.UTC))
+	service := env.Limits
+	user := &models.User{AgeBracket: models.AgeBracketUnder13}
+
+	limit := service.Status(user).EffectiveLimit
+	if err := service.Charge(user, services.SpendPurchase, limit); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Charge(user, services.SpendPurchase, 1); err == nil {
+		t.Fatal("charge over bracket cap succeeded")
+	}
+
+	status := service.Status(user)
+	if want := time.Date(2026, 2, 1, 0, 0, 0, 0, tokyo).Format(time.RFC3339); status.ResetsAt != want {
+		t.Errorf("resets at %s, want %s", status.ResetsAt, want)
+	}
+
+	// 15:30 UTC on Jan 31 is already Feb 1 in Tokyo
+	env.Clock.Set(time.Date(2026, 1, 31, 15, 30, 0, 0, time.UTC))
+	if err := service.Charge(user, services.SpendPurchase, limit); err != nil {
+		t.Errorf("charge after month rollover: %v", err)
+	}
+	if period := service.Status(user).Period; period != "2026-02" {
+		t.Errorf("period %s, want 2026-02", period)
+	}
+}
+
+func TestSelfLimitRaisesWaitForReset(t *testing.T) {
+	env := limitEnv(t, "UTC")
+	env.Clock.Set(time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC))
+	service := env.Limits
+	user := &models.User{AgeBracket: models.AgeBracketAdult}
+
+	// Setting a first limit tightens at once
+	service.SetSelfLimit(user, 1000)
+	if status := service.Status(user); status.EffectiveLimit != 1000 || status.PendingSelfLimit != nil {
+		t.Fatalf("after setting a limit: %+v", status)
+	}
+
+	// Raising or removing it waits for the next month
+	for _, limit := range []int{5000, 0} {
+		service.SetSelfLimit(user, limit)
+		status := service.Status(user)
+		if status.EffectiveLimit != 1000 || status.PendingSelfLimit == nil || *status.PendingSelfLimit != limit {
+			t.Errorf("after raising to %d: %+v", limit, status)
+		}
+		if err := service.Charge(user, services.SpendPurchase, 1001); err == nil {
+			t.Errorf("charge over the current limit succeeded while raising to %d", limit)
+		}
+	}
+
+	// Tightening again applies at once and drops the pending raise
+	service.SetSelfLimit(user, 500)
+	if status := service.Status(user); status.EffectiveLimit != 500 || status.PendingSelfLimit != nil {
+		t.Errorf("after lowering: %+v", status)
+	}
+
+	service.SetSelfLimit(user, 0)
+	env.Clock.Set(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	if status := service.Status(user); status.SelfLimit != 0 || status.EffectiveLimit != 0 || status.PendingSelfLimit != nil {
+		t.Errorf("after the reset: %+v", status)
+	}
+}
+
+func TestRefundAfterResetDoesNotCarryOver(t *testing.T) {
+	env := limitEnv(t, "UTC")
+	env.Clock.Set(time.Date(2026, 3, 31, 23, 59, 0, 0, time.UTC))
+	service := env.Limits
+	user := &models.User{AgeBracket: models.AgeBracketUnder13}
+
+	if err := service.Charge(user, services.SpendPull, 500); err != nil {
+		t.Fatal(err)
+	}
+
+	// The charge failed to go through, but is refunded after the reset
+	env.Clock.Set(time.Date(2026, 4, 1, 0, 1, 0, 0, time.UTC))
+	service.Refund(user, services.SpendPull, 500)
+	service.Refund(user, services.SpendPurchase, 100)
+
+	status := service.Status(user)
+	if status.Period != "2026-04" || status.Spent != 0 || status.Purchased != 0 {
+		t.Errorf("after a refund across the reset: %+v", status)
+	}
+}
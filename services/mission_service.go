@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gacha/logging"
+	"gacha/models"
+)
+
+// SourceMission identifies mission rewards in metrics
+const SourceMission = "mission"
+
+// Errors returned for mission claims that cannot be granted
+var (
+	ErrUnknownMission    = errors.New("unknown mission")
+	ErrMissionIncomplete = errors.New("mission not completed")
+	ErrMissionClaimed    = errors.New("mission reward already claimed")
+)
+
+// MissionService tracks each user's progress on missions and achievements
+// from the pull and inventory events, and grants their rewards when claimed.
+// Daily and weekly missions reset at the calendar boundaries of its timezone.
+type MissionService struct {
+	economyService *EconomyService
+	events         *EventBus
+	pool           []models.Character // Characters own_all objectives count
+	location       *time.Location
+	now            func() time.Time
+	logger         *slog.Logger
+	mu             sync.Mutex // Guards mission progress
+}
+
+// NewMissionService creates a new mission service listening to events
+func NewMissionService(timezone string, events *EventBus, economyService *EconomyService, logger *slog.Logger) (*MissionService, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("load missions timezone: %w", err)
+	}
+
+	s := &MissionService{
+		economyService: economyService,
+		events:         events,
+		pool:           models.DefaultBanner().Characters,
+		location:       location,
+		now:            time.Now,
+		logger:         logger,
+	}
+	events.Subscribe(s.handleEvent)
+	return s, nil
+}
+
+// SetClock replaces the time source of mission periods
+func (s *MissionService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// handleEvent advances the progress of the missions an event counts toward
+func (s *MissionService) handleEvent(ctx context.Context, event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.economyService.Update(event.User, func() {
+		s.advance(ctx, event, s.now())
+	})
+}
+
+// advance records an event's progress, called with mu and the user's lock held
+func (s *MissionService) advance(ctx context.Context, event Event, now time.Time) {
+	for _, mission := range models.GetMissions() {
+		objective := mission.Objective
+		state := s.progress(event.User, mission, now)
+		before := state.Progress
+
+		switch {
+		case event.Kind == EventPull && objective.Kind == models.ObjectivePulls:
+			state.Progress += len(event.Characters)
+		case event.Kind == EventPull && objective.Kind == models.ObjectiveObtainRarity:
+			for _, char := range event.Characters {
+				if char.Rarity >= objective.Rarity {
+					state.Progress++
+				}
+			}
+		case event.Kind == EventInventory && objective.Kind == models.ObjectiveOwnAll:
+			state.Progress = s.owned(event.User, objective.Rarity)
+		}
+		if state.Progress == before {
+			continue
+		}
+
+		target := s.target(mission)
+		state.Progress = min(state.Progress, target)
+		event.User.Missions[mission.ID] = state
+		if before < target && state.Progress >= target {
+			logging.FromContext(ctx, s.logger).InfoContext(ctx, "mission completed", "mission", mission.ID, "period", state.Period)
+		}
+	}
+}
+
+// List returns a user's progress on every mission in its current period
+func (s *MissionService) List(user *models.User) models.MissionsResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	missions := models.GetMissions()
+	response := models.MissionsResponse{Missions: make([]models.MissionStatus, len(missions))}
+	s.economyService.Update(user, func() {
+		for i, mission := range missions {
+			response.Missions[i] = s.status(user, mission, now)
+		}
+	})
+	return response
+}
+
+// Claim grants a completed mission's reward, returning ErrUnknownMission,
+// ErrMissionIncomplete or ErrMissionClaimed when it cannot be granted, and
+// publishes EventMissionClaimed otherwise
+func (s *MissionService) Claim(ctx context.Context, user *models.User, missionID string) (models.MissionStatus, error) {
+	ctx, span := startSpan(ctx, "MissionService.Claim")
+	defer span.End()
+
+	mission, ok := models.FindMission(missionID)
+	if !ok {
+		return models.MissionStatus{}, fmt.Errorf("%w: %q", ErrUnknownMission, missionID)
+	}
+
+	status, err := s.claim(ctx, user, mission)
+	if err == nil {
+		s.events.Publish(ctx, Event{Kind: EventMissionClaimed, User: user, MissionID: mission.ID})
+	}
+	return status, err
+}
+
+// claim grants a mission's reward under mu, which handleEvent takes too, so
+// that progress cannot change between the check and the grant. Progress is
+// read and written under the user's lock, released while granting.
+func (s *MissionService) claim(ctx context.Context, user *models.User, mission models.Mission) (models.MissionStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var status models.MissionStatus
+	s.economyService.Update(user, func() {
+		status = s.status(user, mission, now)
+	})
+	switch {
+	case status.Claimed:
+		return status, ErrMissionClaimed
+	case !status.Completed:
+		return status, ErrMissionIncomplete
+	}
+
+	reason := fmt.Sprintf("mission %s", mission.ID)
+	if err := s.economyService.Grant(ctx, user, models.ActorUserPrefix+user.Username, SourceMission, mission.Reward, reason); err != nil {
+		return status, err
+	}
+
+	var state models.MissionProgress
+	s.economyService.Update(user, func() {
+		state = s.progress(user, mission, now)
+		state.Claimed = true
+		user.Missions[mission.ID] = state
+	})
+	status.Claimed = true
+
+	logging.FromContext(ctx, s.logger).InfoContext(ctx, "mission claimed", "mission", mission.ID, "period", state.Period)
+	return status, nil
+}
+
+// status describes a user's progress on a mission, called with mu and the
+// user's lock held
+func (s *MissionService) status(user *models.User, mission models.Mission, now time.Time) models.MissionStatus {
+	state := s.progress(user, mission, now)
+	target := s.target(mission)
+
+	status := models.MissionStatus{
+		Mission:   mission,
+		Progress:  state.Progress,
+		Target:    target,
+		Completed: state.Progress >= target,
+		Claimed:   state.Claimed,
+	}
+	if resetsAt, ok := s.nextReset(mission.Period, now); ok {
+		status.ResetsAt = resetsAt.Format(time.RFC3339)
+	}
+	return status
+}
+
+// progress returns a user's progress on a mission in the current period,
+// starting afresh once the period has changed, called with mu and the user's
+// lock held
+func (s *MissionService) progress(user *models.User, mission models.Mission, now time.Time) models.MissionProgress {
+	if user.Missions == nil {
+		user.Missions = make(map[string]models.MissionProgress)
+	}
+
+	period := s.period(mission.Period, now)
+	state, ok := user.Missions[mission.ID]
+	if !ok || state.Period != period {
+		state = models.MissionProgress{Period: period}
+	}
+	return state
+}
+
+// target returns the progress that completes a mission
+func (s *MissionService) target(mission models.Mission) int {
+	if mission.Objective.Kind != models.ObjectiveOwnAll {
+		return mission.Objective.Target
+	}
+
+	target := 0
+	for _, char := range s.pool {
+		if char.Rarity == mission.Objective.Rarity {
+			target++
+		}
+	}
+	return target
+}
+
+// owned counts the pool characters of a rarity a user owns
+func (s *MissionService) owned(user *models.User, rarity int) int {
+	count := 0
+	for _, char := range s.pool {
+		if char.Rarity == rarity && user.HasCharacter(char.ID) {
+			count++
+		}
+	}
+	return count
+}
+
+// period returns the key of the mission period containing now
+func (s *MissionService) period(period string, now time.Time) string {
+	local := now.In(s.location)
+	switch period {
+	case models.MissionDaily:
+		return local.Format(time.DateOnly)
+	case models.MissionWeekly:
+		year, week := local.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return ""
+}
+
+// nextReset returns the start of the next mission period, weeks starting on
+// Monday, and false for permanent missions
+func (s *MissionService) nextReset(period string, now time.Time) (time.Time, bool) {
+	local := now.In(s.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.location)
+	switch period {
+	case models.MissionDaily:
+		return midnight.AddDate(0, 0, 1), true
+	case models.MissionWeekly:
+		daysToMonday := (8 - int(local.Weekday())) % 7
+		if daysToMonday == 0 {
+			daysToMonday = 7
+		}
+		return midnight.AddDate(0, 0, daysToMonday), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,126 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gacha/config"
+	"gacha/internal/testenv"
+	"gacha/models"
+	"gacha/services"
+)
+
+// missionStatus finds a mission in a user's mission list
+func missionStatus(t *testing.T, service *services.MissionService, user *models.User, id string) models.MissionStatus {
+	t.Helper()
+	for _, status := range service.List(user).Missions {
+		if status.Mission.ID == id {
+			return status
+		}
+	}
+	t.Fatalf("mission %s not listed", id)
+	return models.MissionStatus{}
+}
+
+func TestPullEventsCompleteMissionsThatResetByPeriod(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	// A Tuesday, so the next day falls in the same ISO week
+	env.Clock.Set(time.Date(2026, 10, 13, 12, 0, 0, 0, time.UTC))
+	economy, service := env.Economy, env.Missions
+	user := &models.User{Username: "missions", Currency: 100000}
+
+	if _, err := economy.PullMany(context.Background(), user, models.PullRequest{Count: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := missionStatus(t, service, user, "daily_pull_10"); !status.Completed || status.Progress != 10 {
+		t.Errorf("daily_pull_10 after ten pulls: %+v", status)
+	}
+	if status := missionStatus(t, service, user, "pulls_100"); status.Completed || status.Progress != 10 {
+		t.Errorf("pulls_100 after ten pulls: %+v", status)
+	}
+
+	if _, err := service.Claim(context.Background(), user, "daily_pull_10"); err != nil {
+		t.Fatal(err)
+	}
+	if user.ItemCount(models.ItemGenericTicket) != 1 {
+		t.Errorf("claim granted %d tickets", user.ItemCount(models.ItemGenericTicket))
+	}
+	if _, err := service.Claim(context.Background(), user, "daily_pull_10"); !errors.Is(err, services.ErrMissionClaimed) {
+		t.Errorf("second claim returned %v", err)
+	}
+	if _, err := service.Claim(context.Background(), user, "pulls_100"); !errors.Is(err, services.ErrMissionIncomplete) {
+		t.Errorf("incomplete claim returned %v", err)
+	}
+	if _, err := service.Claim(context.Background(), user, "no_such_mission"); !errors.Is(err, services.ErrUnknownMission) {
+		t.Errorf("unknown claim returned %v", err)
+	}
+
+	// Daily missions start over the next day, weekly and permanent ones carry on
+	env.Clock.Set(env.Clock.Now().AddDate(0, 0, 1))
+	if status := missionStatus(t, service, user, "daily_pull_10"); status.Progress != 0 || status.Claimed {
+		t.Errorf("daily_pull_10 the next day: %+v", status)
+	}
+	if status := missionStatus(t, service, user, "weekly_pull_50"); status.Progress != 10 {
+		t.Errorf("weekly_pull_50 the next day: %+v", status)
+	}
+	if status := missionStatus(t, service, user, "pulls_100"); status.Progress != 10 || status.ResetsAt != "" {
+		t.Errorf("pulls_100 the next day: %+v", status)
+	}
+}
+
+func TestInventoryEventsCompleteOwnAll(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	env.Clock.Set(time.Date(2026, 10, 13, 12, 0, 0, 0, time.UTC))
+	service := env.Missions
+	user := &models.User{Username: "collector"}
+
+	var added []models.Character
+	for _, char := range models.DefaultBanner().Characters {
+		if char.Rarity == 4 {
+			user.AddCharacter(char)
+			added = append(added, char)
+		}
+	}
+
+	env.Events.Publish(context.Background(), services.Event{Kind: services.EventInventory, User: user, Characters: added[:1]})
+	status := missionStatus(t, service, user, "all_sr")
+	if !status.Completed || status.Progress != len(added) || status.Target != len(added) {
+		t.Errorf("all_sr owning every 4★: %+v", status)
+	}
+	if status := missionStatus(t, service, user, "all_ssr"); status.Completed {
+		t.Errorf("all_ssr completed without any 5★")
+	}
+}
+
+func TestMissionProgressIsWrittenUnderTheUserLock(t *testing.T) {
+	env := testenv.New(t, config.LoadConfig())
+	env.Clock.Set(time.Date(2026, 10, 13, 12, 0, 0, 0, time.UTC))
+	economy := env.Economy
+	user := &models.User{Username: "missions", Currency: 100000}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			if _, err := economy.Pull(context.Background(), user, models.PullTypeSingle); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			economy.Update(user, func() { _ = user.Missions["daily_pull_10"] })
+		}
+	}
+
+	if progress := economy.Snapshot(user).Missions["daily_pull_10"].Progress; progress != 10 {
+		t.Errorf("daily_pull_10 progress %d after twenty pulls", progress)
+	}
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"gacha/config"
+	"gacha/logging"
+	"gacha/models"
+)
+
+// SourceSeason identifies season pass rewards and purchases in metrics
+const SourceSeason = "season"
+
+// Errors returned for season pass requests that cannot be granted
+var (
+	ErrNoActiveSeason  = errors.New("no active season")
+	ErrUnknownTier     = errors.New("unknown season tier")
+	ErrUnknownTrack    = errors.New("unknown season track")
+	ErrTierLocked      = errors.New("season tier not reached")
+	ErrTierClaimed     = errors.New("season tier reward already claimed")
+	ErrPremiumRequired = errors.New("premium season pass required")
+	ErrPremiumOwned    = errors.New("premium season pass already owned")
+)
+
+// SeasonService tracks each user's season pass XP, earned from the pull,
+// login and mission events, and grants tier rewards on the free and premium
+// tracks when claimed. Progress starts afresh with each scheduled season, or
+// with each calendar quarter when none are scheduled.
+type SeasonService struct {
+	economyService *EconomyService
+	xp             models.SeasonXP
+	seasons        []models.Season // Empty for the default quarterly seasons
+	now            func() time.Time
+	logger         *slog.Logger
+	mu             sync.Mutex // Guards season progress
+}
+
+// NewSeasonService creates a new season pass service listening to events
+func NewSeasonService(cfg config.SeasonConfig, events *EventBus, economyService *EconomyService, logger *slog.Logger) *SeasonService {
+	s := &SeasonService{
+		economyService: economyService,
+		xp:             cfg.XP,
+		seasons:        cfg.Seasons,
+		now:            time.Now,
+		logger:         logger,
+	}
+	events.Subscribe(s.handleEvent)
+	return s
+}
+
+// SetClock replaces the time source that picks the active season
+func (s *SeasonService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// handleEvent adds the XP an event earns on the active season
+func (s *SeasonService) handleEvent(ctx context.Context, event Event) {
+	var xp int
+	switch event.Kind {
+	case EventPull:
+		xp = s.xp.Pull * len(event.Characters)
+	case EventLogin:
+		xp = s.xp.Login
+	case EventMissionClaimed:
+		xp = s.xp.Mission
+	}
+	if xp <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	season, ok := s.active(s.now())
+	if !ok {
+		return
+	}
+
+	var before, after int
+	s.economyService.Update(event.User, func() {
+		progress := s.progress(event.User, season)
+		before = tierFor(season, progress.XP)
+		progress.XP += xp
+		after = tierFor(season, progress.XP)
+		event.User.Season = progress
+	})
+	if after > before {
+		logging.FromContext(ctx, s.logger).InfoContext(ctx, "season tier reached", "season", season.ID, "tier", after)
+	}
+}
+
+// Status returns a user's progress on the active season and the next
+// scheduled season
+func (s *SeasonService) Status(user *models.User) models.SeasonStatusResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	response := models.SeasonStatusResponse{Tiers: []models.SeasonTierStatus{}}
+	if upcoming, ok := s.upcoming(now); ok {
+		response.Upcoming = &upcoming
+	}
+
+	season, ok := s.active(now)
+	if !ok {
+		return response
+	}
+
+	var progress models.SeasonProgress
+	s.economyService.Update(user, func() { progress = s.progress(user, season) })
+	response.Active = true
+	response.Season = &season
+	response.XP = progress.XP
+	response.Tier = tierFor(season, progress.XP)
+	response.Premium = progress.Premium
+	for tier := range season.Tiers {
+		response.Tiers = append(response.Tiers, s.tierStatus(season, progress, tier+1))
+	}
+	return response
+}
+
+// ClaimTier grants a reached tier's reward on a track. It returns
+// ErrNoActiveSeason between seasons, ErrUnknownTier or ErrUnknownTrack for a
+// tier or track the season lacks, ErrTierLocked before the tier is reached,
+// ErrPremiumRequired for the premium track without the premium pass and
+// ErrTierClaimed when the reward was already granted.
+func (s *SeasonService) ClaimTier(ctx context.Context, user *models.User, tier int, track string) (models.SeasonTierStatus, error) {
+	ctx, span := startSpan(ctx, "SeasonService.ClaimTier")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	season, ok := s.active(s.now())
+	if !ok {
+		return models.SeasonTierStatus{}, ErrNoActiveSeason
+	}
+	if tier < 1 || tier > len(season.Tiers) {
+		return models.SeasonTierStatus{}, fmt.Errorf("%w: %d", ErrUnknownTier, tier)
+	}
+
+	var progress models.SeasonProgress
+	s.economyService.Update(user, func() { progress = s.progress(user, season) })
+	status := s.tierStatus(season, progress, tier)
+	var reward models.Reward
+	var claimed bool
+	switch track {
+	case models.TrackFree:
+		reward, claimed = status.Free, status.FreeClaimed
+	case models.TrackPremium:
+		reward, claimed = status.Premium, status.PremiumClaimed
+	default:
+		return status, fmt.Errorf("%w: %q", ErrUnknownTrack, track)
+	}
+	switch {
+	case !status.Unlocked:
+		return status, ErrTierLocked
+	case track == models.TrackPremium && !progress.Premium:
+		return status, ErrPremiumRequired
+	case claimed:
+		return status, ErrTierClaimed
+	}
+
+	reason := fmt.Sprintf("season %s tier %d %s", season.ID, tier, track)
+	if err := s.economyService.Grant(ctx, user, models.ActorUserPrefix+user.Username, SourceSeason, reward, reason); err != nil {
+		return status, err
+	}
+
+	if track == models.TrackFree {
+		progress.ClaimedFree = append(progress.ClaimedFree, tier)
+		status.FreeClaimed = true
+	} else {
+		progress.ClaimedPremium = append(progress.ClaimedPremium, tier)
+		status.PremiumClaimed = true
+	}
+	s.economyService.Update(user, func() { user.Season = progress })
+
+	logging.FromContext(ctx, s.logger).InfoContext(ctx, "season tier claimed", "season", season.ID, "tier", tier, "track", track)
+	return status, nil
+}
+
+// UnlockPremium charges the active season's premium price for its premium
+// track, unlocking it for nothing when the price is zero. It returns
+// ErrNoActiveSeason between seasons and ErrPremiumOwned when already
+// unlocked, and the economy's errors when the user cannot pay.
+func (s *SeasonService) UnlockPremium(ctx context.Context, user *models.User) error {
+	ctx, span := startSpan(ctx, "SeasonService.UnlockPremium")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	season, ok := s.active(s.now())
+	if !ok {
+		return ErrNoActiveSeason
+	}
+	var progress models.SeasonProgress
+	s.economyService.Update(user, func() { progress = s.progress(user, season) })
+	if progress.Premium {
+		return ErrPremiumOwned
+	}
+
+	if season.PremiumPrice > 0 {
+		reason := fmt.Sprintf("season %s premium pass", season.ID)
+		if err := s.economyService.Spend(ctx, user, SourceSeason, season.PremiumPrice, reason); err != nil {
+			return err
+		}
+	}
+
+	progress.Premium = true
+	s.economyService.Update(user, func() { user.Season = progress })
+
+	logging.FromContext(ctx, s.logger).InfoContext(ctx, "season premium unlocked", "season", season.ID, "price", season.PremiumPrice)
+	return nil
+}
+
+// active returns the season running at now
+func (s *SeasonService) active(now time.Time) (models.Season, bool) {
+	if len(s.seasons) == 0 {
+		return config.QuarterlySeason(now), true
+	}
+	for _, season := range s.seasons {
+		if !now.Before(season.Start) && now.Before(season.End) {
+			return season, true
+		}
+	}
+	return models.Season{}, false
+}
+
+// upcoming returns the earliest season starting after now
+func (s *SeasonService) upcoming(now time.Time) (models.Season, bool) {
+	if len(s.seasons) == 0 {
+		return config.QuarterlySeason(config.QuarterlySeason(now).End), true
+	}
+	var next models.Season
+	found := false
+	for _, season := range s.seasons {
+		if season.Start.After(now) && (!found || season.Start.Before(next.Start)) {
+			next, found = season, true
+		}
+	}
+	return next, found
+}
+
+// progress returns a copy of a user's progress on a season, starting afresh
+// once the season has changed, called with mu and the user's lock held
+func (s *SeasonService) progress(user *models.User, season models.Season) models.SeasonProgress {
+	if user.Season.SeasonID != season.ID {
+		return models.SeasonProgress{SeasonID: season.ID}
+	}
+	progress := user.Season
+	progress.ClaimedFree = slices.Clone(progress.ClaimedFree)
+	progress.ClaimedPremium = slices.Clone(progress.ClaimedPremium)
+	return progress
+}
+
+// tierStatus describes a user's standing on a tier, numbered from 1
+func (s *SeasonService) tierStatus(season models.Season, progress models.SeasonProgress, tier int) models.SeasonTierStatus {
+	rewards := season.Tiers[tier-1]
+	return models.SeasonTierStatus{
+		Tier:           tier,
+		XPRequired:     tier * season.XPPerTier,
+		Unlocked:       tier <= tierFor(season, progress.XP),
+		Free:           rewards.Free,
+		Premium:        rewards.Premium,
+		FreeClaimed:    slices.Contains(progress.ClaimedFree, tier),
+		PremiumClaimed: slices.Contains(progress.ClaimedPremium, tier),
+	}
+}
+
+// tierFor returns the highest tier an amount of XP reaches
+func tierFor(season models.Season, xp int) int {
+	return min(xp/season.XPPerTier, len(season.Tiers))
+}
@@ -0,0 +1,252 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gacha/config"
+	"gacha/internal/testenv"
+	"gacha/models"
+	"gacha/services"
+)
+
+// seasonSchedule runs two back-to-back seasons of three tiers in October and
+// November 2026, and one in January 2027 after a gap
+func seasonSchedule() config.SeasonConfig {
+	tiers := []models.SeasonTier{
+		{Free: models.Reward{Currency: 100}, Premium: models.Reward{Items: map[string]int{models.ItemGenericTicket: 1}}},
+		{Free: models.Reward{Currency: 200}, Premium: models.Reward{Items: map[string]int{models.ItemGenericTicket: 2}}},
+		{Free: models.Reward{Currency: 300}, Premium: models.Reward{Items: map[string]int{models.ItemGenericTicket: 3}}},
+	}
+	month := func(id string, year int, m time.Month) models.Season {
+		start := time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+		return models.Season{ID: id, Start: start, End: start.AddDate(0, 1, 0), XPPerTier: 100, PremiumPrice: 500, Tiers: tiers}
+	}
+	return config.SeasonConfig{
+		XP:      models.SeasonXP{Pull: 10, Login: 50, Mission: 100},
+		Seasons: []models.Season{month("october", 2026, time.October), month("november", 2026, time.November), month("january", 2027, time.January)},
+	}
+}
+
+// seasonEnv returns services running schedule
+func seasonEnv(t *testing.T, schedule config.SeasonConfig) *testenv.Env {
+	t.Helper()
+	cfg := config.LoadConfig()
+	cfg.Season = schedule
+	return testenv.New(t, cfg)
+}
+
+func TestEventsEarnSeasonXP(t *testing.T) {
+	env := seasonEnv(t, seasonSchedule())
+	env.Clock.Set(time.Date(2026, 10, 13, 12, 0, 0, 0, time.UTC))
+	economy, service := env.Economy, env.Seasons
+	user := &models.User{Username: "season", Currency: 100000}
+	ctx := context.Background()
+
+	if _, err := economy.Pull(ctx, user, models.PullTypeTen); err != nil {
+		t.Fatal(err)
+	}
+	env.Events.Publish(ctx, services.Event{Kind: services.EventLogin, User: user})
+	env.Events.Publish(ctx, services.Event{Kind: services.EventMissionClaimed, User: user, MissionID: "daily_pull_1"})
+
+	status := service.Status(user)
+	if !status.Active || status.Season.ID != "october" || status.XP != 10*10+50+100 {
+		t.Fatalf("status %+v after a ten pull, login and mission", status)
+	}
+	if status.Tier != 2 || !status.Tiers[1].Unlocked || status.Tiers[2].Unlocked {
+		t.Errorf("tier %d at %d XP", status.Tier, status.XP)
+	}
+	if status.Upcoming == nil || status.Upcoming.ID != "november" {
+		t.Errorf("upcoming season %+v", status.Upcoming)
+	}
+
+	// Progress never passes the last tier
+	for range 10 {
+		env.Events.Publish(ctx, services.Event{Kind: services.EventMissionClaimed, User: user})
+	}
+	if status := service.Status(user); status.Tier != 3 {
+		t.Errorf("tier %d at %d XP", status.Tier, status.XP)
+	}
+}
+
+func TestClaimTierOnFreeAndPremiumTracks(t *testing.T) {
+	env := seasonEnv(t, seasonSchedule())
+	env.Clock.Set(time.Date(2026, 10, 13, 12, 0, 0, 0, time.UTC))
+	service := env.Seasons
+	user := &models.User{Username: "claims", Currency: 1000}
+	ctx := context.Background()
+
+	env.Events.Publish(ctx, services.Event{Kind: services.EventMissionClaimed, User: user})
+
+	if _, err := service.ClaimTier(ctx, user, 2, models.TrackFree); !errors.Is(err, services.ErrTierLocked) {
+		t.Errorf("claim of an unreached tier returned %v", err)
+	}
+	if _, err := service.ClaimTier(ctx, user, 4, models.TrackFree); !errors.Is(err, services.ErrUnknownTier) {
+		t.Errorf("claim of a missing tier returned %v", err)
+	}
+	if _, err := service.ClaimTier(ctx, user, 1, models.TrackPremium); !errors.Is(err, services.ErrPremiumRequired) {
+		t.Errorf("premium claim without the pass returned %v", err)
+	}
+
+	status, err := service.ClaimTier(ctx, user, 1, models.TrackFree)
+	if err != nil || !status.FreeClaimed || user.Currency != 1100 {
+		t.Fatalf("free claim returned %+v, %v with balance %d", status, err, user.Currency)
+	}
+	if _, err := service.ClaimTier(ctx, user, 1, models.TrackFree); !errors.Is(err, services.ErrTierClaimed) {
+		t.Errorf("second free claim returned %v", err)
+	}
+
+	// The pass is charged once, and opens the premium track of reached tiers
+	if err := service.UnlockPremium(ctx, user); err != nil || user.Currency != 600 {
+		t.Fatalf("unlock returned %v with balance %d", err, user.Currency)
+	}
+	if err := service.UnlockPremium(ctx, user); !errors.Is(err, services.ErrPremiumOwned) || user.Currency != 600 {
+		t.Errorf("second unlock returned %v with balance %d", err, user.Currency)
+	}
+	status, err = service.ClaimTier(ctx, user, 1, models.TrackPremium)
+	if err != nil || !status.PremiumClaimed || user.ItemCount(models.ItemGenericTicket) != 1 {
+		t.Errorf("premium claim returned %+v, %v", status, err)
+	}
+}
+
+func TestUnlockPremiumRequiresCurrency(t *testing.T) {
+	env := seasonEnv(t, seasonSchedule())
+	env.Clock.Set(time.Date(2026, 10, 13, 12, 0, 0, 0, time.UTC))
+	service := env.Seasons
+	user := &models.User{Username: "broke", Currency: 499}
+
+	if err := service.UnlockPremium(context.Background(), user); !errors.Is(err, services.ErrInsufficientCurrency) {
+		t.Fatalf("unlock returned %v", err)
+	}
+	if user.Currency != 499 || service.Status(user).Premium {
+		t.Errorf("failed unlock left balance %d, premium %v", user.Currency, service.Status(user).Premium)
+	}
+}
+
+func TestSeasonProgressResetsWithEachSeason(t *testing.T) {
+	env := seasonEnv(t, seasonSchedule())
+	env.Clock.Set(time.Date(2026, 10, 31, 23, 0, 0, 0, time.UTC))
+	service := env.Seasons
+	user := &models.User{Username: "rollover", Currency: 1000}
+	ctx := context.Background()
+
+	env.Events.Publish(ctx, services.Event{Kind: services.EventMissionClaimed, User: user})
+	if err := service.UnlockPremium(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	env.Clock.Advance(2 * time.Hour)
+	status := service.Status(user)
+	if status.Season.ID != "november" || status.XP != 0 || status.Premium {
+		t.Errorf("new season starts with %d XP, premium %v", status.XP, status.Premium)
+	}
+
+	// No season runs in December, so events earn nothing and claims fail
+	env.Clock.Set(time.Date(2026, 12, 15, 0, 0, 0, 0, time.UTC))
+	env.Events.Publish(ctx, services.Event{Kind: services.EventMissionClaimed, User: user})
+	status = service.Status(user)
+	if status.Active || status.Season != nil || status.Upcoming.ID != "january" {
+		t.Errorf("status %+v between seasons", status)
+	}
+	if _, err := service.ClaimTier(ctx, user, 1, models.TrackFree); !errors.Is(err, services.ErrNoActiveSeason) {
+		t.Errorf("claim between seasons returned %v", err)
+	}
+	if err := service.UnlockPremium(ctx, user); !errors.Is(err, services.ErrNoActiveSeason) {
+		t.Errorf("unlock between seasons returned %v", err)
+	}
+}
+
+func TestDailyAndMissionClaimsPublishSeasonEvents(t *testing.T) {
+	env := seasonEnv(t, seasonSchedule())
+	env.Clock.Set(time.Date(2026, 10, 13, 12, 0, 0, 0, time.UTC))
+	economy, service := env.Economy, env.Seasons
+	missions, daily := env.Missions, env.Daily
+	user := &models.User{Username: "events", Currency: 100000}
+	ctx := context.Background()
+
+	if _, err := daily.Claim(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := economy.Pull(ctx, user, models.PullTypeSingle); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := missions.Claim(ctx, user, "daily_pull_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if xp := service.Status(user).XP; xp != 50+10+100 {
+		t.Errorf("%d XP after a login, a pull and a mission", xp)
+	}
+}
+
+func TestSeasonProgressIsWrittenUnderTheUserLock(t *testing.T) {
+	env := seasonEnv(t, seasonSchedule())
+	env.Clock.Set(time.Date(2026, 10, 13, 12, 0, 0, 0, time.UTC))
+	economy, service := env.Economy, env.Seasons
+	user := &models.User{Username: "season", Currency: 100000}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			if _, err := economy.Pull(context.Background(), user, models.PullTypeSingle); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			economy.Update(user, func() { _ = user.Season.XP })
+		}
+	}
+
+	if status := service.Status(user); status.XP != 20*10 {
+		t.Errorf("%d XP after twenty pulls", status.XP)
+	}
+}
+
+func TestUnlockFreePremiumSpendsNothing(t *testing.T) {
+	schedule := seasonSchedule()
+	schedule.Seasons[0].PremiumPrice = 0
+	env := seasonEnv(t, schedule)
+	env.Clock.Set(time.Date(2026, 10, 13, 12, 0, 0, 0, time.UTC))
+	service := env.Seasons
+	user := &models.User{Username: "free", Currency: 0}
+
+	if err := service.UnlockPremium(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	if !service.Status(user).Premium || user.Spending.Spent != 0 {
+		t.Errorf("free unlock left premium %v, spent %d", service.Status(user).Premium, user.Spending.Spent)
+	}
+}
+
+func TestQuarterlySeasonsRunWithoutASchedule(t *testing.T) {
+	env := seasonEnv(t, config.SeasonConfig{XP: models.SeasonXP{Pull: 10}})
+	env.Clock.Set(time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC))
+	economy, service := env.Economy, env.Seasons
+	user := &models.User{Username: "quarterly", Currency: 100000}
+
+	if _, err := economy.Pull(context.Background(), user, models.PullTypeSingle); err != nil {
+		t.Fatal(err)
+	}
+	status := service.Status(user)
+	if !status.Active || status.Season.ID != "season-2026-q4" || status.XP != 10 {
+		t.Fatalf("status %+v at the end of 2026", status)
+	}
+	if status.Upcoming == nil || status.Upcoming.ID != "season-2027-q1" {
+		t.Errorf("upcoming season %+v", status.Upcoming)
+	}
+
+	// Seasons keep coming however long the server runs
+	env.Clock.Set(time.Date(2031, 5, 1, 0, 0, 0, 0, time.UTC))
+	if status := service.Status(user); !status.Active || status.Season.ID != "season-2031-q2" || status.XP != 0 {
+		t.Errorf("status %+v years later", status)
+	}
+}
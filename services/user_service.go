@@ -1,55 +1,96 @@
 package services
 
 import (
-	"gacha/models"
+	"context"
+	"errors"
+	"log/slog"
 	"sync"
+
+	"gacha/logging"
+	"gacha/models"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// UserUpdate describes a change to a user's state
+type UserUpdate struct {
+	Username      string
+	NewCharacters []models.Character  // Characters newly added to the inventory
+	DailyReward   *models.DailyReward // Daily login reward just granted
+}
+
+// UserListener is called after a user's state changes, with the context of
+// the operation that changed it
+type UserListener func(ctx context.Context, update UserUpdate)
+
 // UserService handles user management
 type UserService struct {
-	users map[string]*models.User
-	mu    sync.RWMutex
+	users     map[string]*models.User
+	listeners []UserListener
+	logger    *slog.Logger
+	mu        sync.RWMutex
 }
 
 // NewUserService creates a new user service
-func NewUserService() *UserService {
+func NewUserService(logger *slog.Logger) *UserService {
 	service := &UserService{
-		users: make(map[string]*models.User),
+		users:  make(map[string]*models.User),
+		logger: logger,
 	}
 
 	// Initialize default user
 	service.users["default"] = &models.User{
-		ID:        1,
-		Username:  "default",
-		Currency:  10000,
-		Inventory: []models.Character{},
-		PityCount: 0,
+		ID:         1,
+		Username:   "default",
+		Currency:   10000,
+		Inventory:  []models.Character{},
+		PityCount:  0,
+		AgeBracket: models.AgeBracketAdult,
 	}
 
 	return service
 }
 
 // GetUser retrieves a user by username
-func (s *UserService) GetUser(username string) *models.User {
+func (s *UserService) GetUser(ctx context.Context, username string) *models.User {
+	_, span := startSpan(ctx, "UserService.GetUser")
+	defer span.End()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.users[username]
 }
 
+// Ping checks that the user store can be read
+func (s *UserService) Ping() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.users == nil {
+		return errors.New("user store not initialized")
+	}
+	return nil
+}
+
 // GetDefaultUser retrieves the default user
-func (s *UserService) GetDefaultUser() *models.User {
-	return s.GetUser("default")
+func (s *UserService) GetDefaultUser(ctx context.Context) *models.User {
+	return s.GetUser(ctx, "default")
 }
 
 // UpdateUser updates user information
-func (s *UserService) UpdateUser(username string, user *models.User) {
+func (s *UserService) UpdateUser(ctx context.Context, username string, user *models.User) {
+	_, span := startSpan(ctx, "UserService.UpdateUser")
+	defer span.End()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.users[username] = user
 }
 
-// CreateUser creates a new user
-func (s *UserService) CreateUser(username string) *models.User {
+// CreateUser creates a new user in the given age bracket
+func (s *UserService) CreateUser(ctx context.Context, username, ageBracket string) *models.User {
+	ctx, span := startSpan(ctx, "UserService.CreateUser")
+	defer span.End()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -58,13 +99,46 @@ func (s *UserService) CreateUser(username string) *models.User {
 	}
 
 	user := &models.User{
-		ID:        len(s.users) + 1,
-		Username:  username,
-		Currency:  1000,
-		Inventory: []models.Character{},
-		PityCount: 0,
+		ID:         len(s.users) + 1,
+		Username:   username,
+		Currency:   1000,
+		Inventory:  []models.Character{},
+		PityCount:  0,
+		AgeBracket: ageBracket,
 	}
 
 	s.users[username] = user
+	span.SetAttributes(attribute.Int("user.id", user.ID))
+	s.logger.InfoContext(ctx, "user registered", logging.UserIDKey, user.ID, "age_bracket", ageBracket)
 	return user
 }
+
+// Subscribe registers a listener for user state changes
+func (s *UserService) Subscribe(listener UserListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// NotifyUpdate notifies all listeners that a user's state has changed
+func (s *UserService) NotifyUpdate(ctx context.Context, username string, newChars []models.Character) {
+	s.Notify(ctx, UserUpdate{
+		Username:      username,
+		NewCharacters: newChars,
+	})
+}
+
+// Notify notifies all listeners of an update to a user's state
+func (s *UserService) Notify(ctx context.Context, update UserUpdate) {
+	ctx, span := startSpan(ctx, "UserService.NotifyUpdate")
+	defer span.End()
+
+	s.mu.RLock()
+	listeners := make([]UserListener, len(s.listeners))
+	copy(listeners, s.listeners)
+	s.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(ctx, update)
+	}
+}